@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yourusername/car-listing-service/config"
+)
+
+func runBackfill(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close(context.Background())
+
+	updated, err := a.service.BackfillCars(ctx)
+	fmt.Fprintf(os.Stderr, "updated %d listings\n", updated)
+	return err
+}
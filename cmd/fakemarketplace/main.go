@@ -0,0 +1,40 @@
+// Command fakemarketplace serves the fake Marketplace feed on a fixed address
+// so the scraper can be pointed at it with SCRAPER_TARGET_URL and
+// SCRAPER_LOGIN_URL.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/yourusername/car-listing-service/testutil/fakemarketplace"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8090", "listen address")
+	listings := flag.Int("listings", 200, "unique listings in the feed")
+	pageSize := flag.Int("page-size", 24, "listings rendered per load")
+	duplicates := flag.Int("duplicates", 4, "earlier listings re-rendered with each load")
+	loadDelay := flag.Duration("load-delay", 0, "latency before a lazy-loaded page appears")
+	endOfFeed := flag.String("end", string(fakemarketplace.EndStop), "end-of-feed behaviour: stop or repeat")
+	sellers := flag.Int("sellers", 0, "sellers the listings are spread over; 0 gives each listing its own")
+	requireLogin := flag.Bool("require-login", true, "redirect to the login page without a session cookie")
+	flag.Parse()
+
+	server := fakemarketplace.NewUnstarted(fakemarketplace.Options{
+		Listings:     *listings,
+		PageSize:     *pageSize,
+		Duplicates:   *duplicates,
+		LoadDelay:    *loadDelay,
+		EndOfFeed:    fakemarketplace.EndOfFeed(*endOfFeed),
+		RequireLogin: *requireLogin,
+		Sellers:      *sellers,
+	})
+	email, password := server.Credentials()
+
+	log.Printf("SCRAPER_TARGET_URL=http://%s/marketplace/manila/cars", *addr)
+	log.Printf("SCRAPER_LOGIN_URL=http://%s/login", *addr)
+	log.Printf("FACEBOOK_EMAIL=%s FACEBOOK_PASSWORD=%s", email, password)
+	log.Fatal(http.ListenAndServe(*addr, server.Handler()))
+}
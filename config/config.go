@@ -8,14 +8,43 @@ import (
 )
 
 type Config struct {
-	ServerPort  string
-	DBHost      string
-	DBPort      string
-	DBUser      string
-	DBPassword  string
-	DBName      string
+	ServerPort        string
+	DBHost            string
+	DBPort            string
+	DBUser            string
+	DBPassword        string
+	DBName            string
+	DBSSLMode         string
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBConnMaxIdleTime time.Duration
+	DBConnectAttempts int
+	DBConnectBackoff  time.Duration
+	DBAutoMigrate     bool
+	DBReadTimeout     time.Duration
+	DBWriteTimeout    time.Duration
+	DBBatchTimeout    time.Duration
+	ReadinessTimeout  time.Duration
+	TrashRetention    time.Duration
+	TrashPurgeEvery   time.Duration
+	BatchMaxOps       int
+	ImportMaxBytes    int
+	Environment       string
+	Scraper           ScraperConfig
+	Tracing           TracingConfig
+	Log               LogConfig
+}
+
+type LogConfig struct {
+	Level  string
+	Format string
+}
+
+type TracingConfig struct {
+	Exporter    string
+	SampleRatio float64
 	Environment string
-	Scraper     ScraperConfig
 }
 
 type ScraperConfig struct {
@@ -27,18 +56,57 @@ type ScraperConfig struct {
 	MaxConsecutiveNoNew     int
 	MaxConsecutiveUnchanged int
 	ExtractionInterval      int
+	CopyThreshold           int
+	RecordDir               string
+	ReplayDir               string
+	TargetURL               string
+	LoginURL                string
+	CookieFile              string
+	Headless                bool
+	// FetchSellers opens the page of every new listing to read the seller's
+	// profile ID, which one scroll of the feed does not show.
+	FetchSellers bool
+	// The credentials are never serialized, so scrape recordings, which
+	// store the config, do not leak them.
+	FacebookEmail    string `json:"-"`
+	FacebookPassword string `json:"-"`
 }
 
 func LoadConfig() *Config {
 	return &Config{
-		ServerPort:  getEnv("SERVER_PORT", "3001"),
-		DBHost:      getEnv("DB_HOST", "localhost"),
-		DBPort:      getEnv("DB_PORT", "5432"),
-		DBUser:      getEnv("DB_USER", "postgres"),
-		DBPassword:  getEnv("DB_PASSWORD", ""),
-		DBName:      getEnv("DB_NAME", "car_listing"),
-		Environment: getEnv("ENVIRONMENT", "development"),
-		Scraper:     loadScraperConfig(),
+		ServerPort:        getEnv("SERVER_PORT", "3001"),
+		DBHost:            getEnv("DB_HOST", "localhost"),
+		DBPort:            getEnv("DB_PORT", "5432"),
+		DBUser:            getEnv("DB_USER", "postgres"),
+		DBPassword:        getEnv("DB_PASSWORD", ""),
+		DBName:            getEnv("DB_NAME", "car_listing"),
+		DBSSLMode:         getEnv("DB_SSLMODE", "disable"),
+		DBMaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 10),
+		DBConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBConnMaxIdleTime: getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		DBConnectAttempts: getEnvInt("DB_CONNECT_ATTEMPTS", 10),
+		DBConnectBackoff:  getEnvDuration("DB_CONNECT_BACKOFF", time.Second),
+		DBAutoMigrate:     getEnvBool("DB_AUTO_MIGRATE", true),
+		DBReadTimeout:     getEnvDuration("DB_READ_TIMEOUT", 5*time.Second),
+		DBWriteTimeout:    getEnvDuration("DB_WRITE_TIMEOUT", 5*time.Second),
+		DBBatchTimeout:    getEnvDuration("DB_BATCH_TIMEOUT", time.Minute),
+		ReadinessTimeout:  getEnvDuration("READINESS_TIMEOUT", 2*time.Second),
+		TrashRetention:    getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeEvery:   getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		BatchMaxOps:       getEnvInt("BATCH_MAX_OPERATIONS", 500),
+		ImportMaxBytes:    getEnvInt("IMPORT_MAX_BYTES", 32<<20),
+		Environment:       getEnv("ENVIRONMENT", "development"),
+		Scraper:           loadScraperConfig(),
+		Tracing: TracingConfig{
+			Exporter:    getEnv("TRACING_EXPORTER", "none"),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1.0),
+			Environment: getEnv("ENVIRONMENT", "development"),
+		},
+		Log: LogConfig{
+			Level:  getEnv("LOG_LEVEL", "info"),
+			Format: getEnv("LOG_FORMAT", "json"),
+		},
 	}
 }
 
@@ -52,6 +120,16 @@ func loadScraperConfig() ScraperConfig {
 		MaxConsecutiveNoNew:     getEnvInt("SCRAPER_MAX_CONSECUTIVE_NO_NEW", 10),
 		MaxConsecutiveUnchanged: getEnvInt("SCRAPER_MAX_CONSECUTIVE_UNCHANGED", 10),
 		ExtractionInterval:      getEnvInt("SCRAPER_EXTRACTION_INTERVAL", 5),
+		CopyThreshold:           getEnvInt("SCRAPER_COPY_THRESHOLD", 500),
+		RecordDir:               os.Getenv("SCRAPER_RECORD_DIR"),
+		ReplayDir:               os.Getenv("SCRAPER_REPLAY_DIR"),
+		TargetURL:               getEnv("SCRAPER_TARGET_URL", "https://www.facebook.com/marketplace/manila/cars?minPrice=350000&exact=false"),
+		LoginURL:                getEnv("SCRAPER_LOGIN_URL", "https://www.facebook.com/login"),
+		CookieFile:              getEnv("SCRAPER_COOKIE_FILE", "facebook_cookies.json"),
+		Headless:                getEnvBool("SCRAPER_HEADLESS", false),
+		FetchSellers:            getEnvBool("SCRAPER_FETCH_SELLERS", false),
+		FacebookEmail:           getEnv("FACEBOOK_EMAIL", ""),
+		FacebookPassword:        getEnv("FACEBOOK_PASSWORD", ""),
 	}
 }
 
@@ -75,6 +153,26 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+		log.Printf("Warning: %s is not a valid number, using default: %v", key, defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+		log.Printf("Warning: %s is not a valid boolean, using default: %t", key, defaultValue)
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -1 +1,18 @@
 package config
+
+import (
+	"net"
+	"net/url"
+)
+
+// DatabaseURL builds the lib/pq connection URL from the DB_* settings.
+func (c *Config) DatabaseURL() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.DBUser, c.DBPassword),
+		Host:     net.JoinHostPort(c.DBHost, c.DBPort),
+		Path:     "/" + c.DBName,
+		RawQuery: url.Values{"sslmode": {c.DBSSLMode}}.Encode(),
+	}
+	return u.String()
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigDatabase(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PORT", "6432")
+	t.Setenv("DB_USER", "cars")
+	t.Setenv("DB_PASSWORD", "p@ss:w/rd")
+	t.Setenv("DB_NAME", "listings")
+	t.Setenv("DB_SSLMODE", "require")
+	t.Setenv("DB_MAX_OPEN_CONNS", "7")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "90s")
+	t.Setenv("DB_CONNECT_ATTEMPTS", "not a number")
+
+	cfg := LoadConfig()
+	if cfg.DBMaxOpenConns != 7 || cfg.DBConnMaxLifetime != 90*time.Second {
+		t.Errorf("pool = %d open, %v lifetime, want 7 and 90s", cfg.DBMaxOpenConns, cfg.DBConnMaxLifetime)
+	}
+	if cfg.DBConnectAttempts != 10 {
+		t.Errorf("connect attempts = %d, want the default 10 for an invalid value", cfg.DBConnectAttempts)
+	}
+
+	// The password has characters that must be escaped to round-trip.
+	u, err := url.Parse(cfg.DatabaseURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	password, _ := u.User.Password()
+	if u.Host != "db.internal:6432" || u.User.Username() != "cars" || password != "p@ss:w/rd" ||
+		u.Path != "/listings" || u.Query().Get("sslmode") != "require" {
+		t.Errorf("DatabaseURL() = %s", cfg.DatabaseURL())
+	}
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/middleware"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/services"
+	"github.com/yourusername/car-listing-service/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type batchItemError struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Errors  []apperror.FieldError  `json:"errors,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+type batchResult struct {
+	Index  int             `json:"index"`
+	Op     models.BatchOp  `json:"op"`
+	Status int             `json:"status"`
+	ID     int             `json:"id,omitempty"`
+	Car    *models.Car     `json:"car,omitempty"`
+	Error  *batchItemError `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Mode      models.BatchMode `json:"mode"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []batchResult    `json:"results"`
+}
+
+// CarsAction dispatches custom methods on the cars collection, such as
+// POST /api/v1/cars:batch. gin only unescapes a literal colon in a route when
+// the engine is started with Run, so the suffix is matched here instead.
+func (ctrl *CarController) CarsAction(c *gin.Context) {
+	switch c.Param("action") {
+	case ":batch":
+		ctrl.BatchCars(c)
+	default:
+		middleware.NoRoute(c)
+	}
+}
+
+// BatchCars applies up to batchLimit create, update and delete operations.
+// Atomic batches are validated up front and either all commit or none do;
+// best-effort batches report a status for every operation.
+func (ctrl *CarController) BatchCars(c *gin.Context) {
+	var req models.BatchRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = models.BatchAtomic
+	}
+	if len(req.Operations) > ctrl.batchLimit {
+		c.Error(apperror.Validation("too_many_operations", "Batch is too large", apperror.FieldError{
+			Field:   "operations",
+			Code:    "max",
+			Message: fmt.Sprintf("must contain at most %d items", ctrl.batchLimit),
+		}))
+		return
+	}
+
+	results := make([]batchResult, len(req.Operations))
+	var valid []models.BatchOperation
+	var validIndex []int
+	var invalidFields []apperror.FieldError
+	for i, op := range req.Operations {
+		results[i] = batchResult{Index: i, Op: op.Op, ID: op.ID}
+		if err := validation.Struct(&op); err != nil {
+			appErr := apperror.From(err)
+			results[i].Status = middleware.StatusFor(appErr.Kind)
+			results[i].Error = newBatchItemError(appErr)
+			for _, field := range appErr.Fields {
+				field.Field = "operations[" + strconv.Itoa(i) + "]." + field.Field
+				invalidFields = append(invalidFields, field)
+			}
+			continue
+		}
+		valid = append(valid, op)
+		validIndex = append(validIndex, i)
+	}
+
+	if req.Mode == models.BatchAtomic && len(invalidFields) > 0 {
+		c.Error(apperror.Validation("validation_failed", "Batch failed validation; nothing was applied", invalidFields...))
+		return
+	}
+
+	outcomes, err := ctrl.service.ApplyBatch(c.Request.Context(), req.Mode, valid)
+	if err != nil {
+		c.Error(batchError(err, validIndex))
+		return
+	}
+
+	for j, outcome := range outcomes {
+		result := &results[validIndex[j]]
+		if outcome.Err != nil {
+			appErr := apperror.From(outcome.Err)
+			if appErr.Kind == apperror.KindInternal || appErr.Kind == apperror.KindUnavailable {
+				slog.ErrorContext(c.Request.Context(), "batch operation failed", "index", result.Index, "op", result.Op, "error", outcome.Err)
+			}
+			result.Status = middleware.StatusFor(appErr.Kind)
+			result.Error = newBatchItemError(appErr)
+			continue
+		}
+
+		result.Status = http.StatusOK
+		if result.Op == models.BatchCreate {
+			result.Status = http.StatusCreated
+		}
+		if outcome.Car != nil {
+			result.ID = outcome.Car.ID
+			result.Car = outcome.Car
+		}
+	}
+
+	resp := batchResponse{Mode: req.Mode, Results: results}
+	for _, result := range results {
+		if result.Error != nil {
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// batchError names the request index of the operation that aborted an
+// atomic batch, keeping the operation's own error code and details.
+func batchError(err error, validIndex []int) error {
+	var opErr *services.BatchOpError
+	if !errors.As(err, &opErr) {
+		return err
+	}
+
+	appErr := *apperror.From(opErr.Err)
+	details := map[string]interface{}{
+		"index": validIndex[opErr.Index],
+		"op":    opErr.Op,
+	}
+	for k, v := range appErr.Details {
+		details[k] = v
+	}
+	appErr.Message = fmt.Sprintf("Operation %d failed; nothing was applied: %s", validIndex[opErr.Index], appErr.Message)
+	appErr.Details = details
+	return &appErr
+}
+
+func newBatchItemError(appErr *apperror.Error) *batchItemError {
+	return &batchItemError{
+		Code:    appErr.Code,
+		Message: appErr.Message,
+		Errors:  appErr.Fields,
+		Details: appErr.Details,
+	}
+}
@@ -0,0 +1,135 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/middleware"
+	"github.com/yourusername/car-listing-service/models"
+)
+
+const batchCar = `{"title": "2018 Toyota Vios", "price": "₱450,000", "link": "https://www.facebook.com/marketplace/item/%d/"}`
+
+func batchService() *fakeCarService {
+	return &fakeCarService{cars: map[int]*models.Car{1: {ID: 1, Title: "2016 Honda City", Version: 1}}}
+}
+
+func TestBatchBestEffort(t *testing.T) {
+	service := batchService()
+	router := newRouter(t, service)
+
+	body := `{"mode": "best_effort", "operations": [
+		{"op": "create", "car": ` + fmt.Sprintf(batchCar, 10) + `},
+		{"op": "delete", "id": 99},
+		{"op": "update", "id": 1, "car": {"title": "2016 Honda City", "price": "cheap", "link": "https://www.facebook.com/marketplace/item/1/"}},
+		{"op": "delete", "id": 1}
+	]}`
+	recorder := serve(router, "POST", "/api/v1/cars:batch", body)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body)
+	}
+
+	var resp struct {
+		Succeeded int `json:"succeeded"`
+		Failed    int `json:"failed"`
+		Results   []struct {
+			Index  int `json:"index"`
+			Status int `json:"status"`
+			ID     int `json:"id"`
+			Error  *struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Succeeded != 2 || resp.Failed != 2 || len(resp.Results) != 4 {
+		t.Fatalf("response = %s", recorder.Body)
+	}
+	want := []struct {
+		status int
+		code   string
+	}{
+		{http.StatusCreated, ""},
+		{http.StatusNotFound, "car_not_found"},
+		{http.StatusBadRequest, "validation_failed"},
+		{http.StatusOK, ""},
+	}
+	for i, result := range resp.Results {
+		code := ""
+		if result.Error != nil {
+			code = result.Error.Code
+		}
+		if result.Index != i || result.Status != want[i].status || code != want[i].code {
+			t.Errorf("result %d = %+v, code %q, want status %d, code %q", i, result, code, want[i].status, want[i].code)
+		}
+	}
+	if resp.Results[0].ID != 2 {
+		t.Errorf("created ID = %d, want 2", resp.Results[0].ID)
+	}
+
+	// The invalid update never reaches the service.
+	if len(service.batches) != 1 || len(service.batches[0]) != 3 {
+		t.Errorf("service got batches %+v, want the three valid operations", service.batches)
+	}
+}
+
+func TestBatchAtomic(t *testing.T) {
+	problem := func(t *testing.T, body string) middleware.Problem {
+		t.Helper()
+		var problem middleware.Problem
+		if err := json.Unmarshal([]byte(body), &problem); err != nil {
+			t.Fatal(err)
+		}
+		return problem
+	}
+
+	t.Run("invalid operation", func(t *testing.T) {
+		service := batchService()
+		body := `{"operations": [
+			{"op": "create", "car": ` + fmt.Sprintf(batchCar, 10) + `},
+			{"op": "create", "car": {"title": "2018 Toyota Vios", "price": "cheap", "link": "https://www.facebook.com/marketplace/item/11/"}}
+		]}`
+		recorder := serve(newRouter(t, service), "POST", "/api/v1/cars:batch", body)
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body)
+		}
+		p := problem(t, recorder.Body.String())
+		if p.Code != "validation_failed" || len(p.Errors) != 1 || !strings.HasPrefix(p.Errors[0].Field, "operations[1].") {
+			t.Errorf("problem = %+v, want a field error under operations[1]", p)
+		}
+		if len(service.batches) != 0 {
+			t.Errorf("service called with %+v", service.batches)
+		}
+	})
+
+	t.Run("failing operation", func(t *testing.T) {
+		body := `{"mode": "atomic", "operations": [
+			{"op": "create", "car": ` + fmt.Sprintf(batchCar, 10) + `},
+			{"op": "delete", "id": 99}
+		]}`
+		recorder := serve(newRouter(t, batchService()), "POST", "/api/v1/cars:batch", body)
+		if recorder.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body)
+		}
+		p := problem(t, recorder.Body.String())
+		if p.Code != "car_not_found" || p.Details["index"] != float64(1) || p.Details["op"] != "delete" {
+			t.Errorf("problem = %+v, want car_not_found at index 1", p)
+		}
+	})
+
+	t.Run("too many operations", func(t *testing.T) {
+		ops := make([]string, 11)
+		for i := range ops {
+			ops[i] = fmt.Sprintf(`{"op": "delete", "id": %d}`, i+1)
+		}
+		recorder := serve(newRouter(t, batchService()), "POST", "/api/v1/cars:batch", `{"operations": [`+strings.Join(ops, ",")+`]}`)
+		if p := problem(t, recorder.Body.String()); recorder.Code != http.StatusBadRequest || p.Code != "too_many_operations" {
+			t.Errorf("status = %d, problem %+v, want too_many_operations", recorder.Code, p)
+		}
+	})
+}
@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"github.com/yourusername/car-listing-service/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// bindJSON decodes the request body into obj and converts decode and
+// validation failures into a validation error listing each bad field.
+func bindJSON(c *gin.Context, obj interface{}) error {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		return validation.Error(err)
+	}
+	return nil
+}
@@ -1,27 +1,94 @@
 package controllers
 
 import (
-	"database/sql"
+	"context"
 	"net/http"
 	"strconv"
 
+	"github.com/yourusername/car-listing-service/apperror"
 	"github.com/yourusername/car-listing-service/models"
 	"github.com/yourusername/car-listing-service/services"
+	"github.com/yourusername/car-listing-service/validation"
 	"github.com/gin-gonic/gin"
 )
 
 type CarController struct {
-	service services.CarService
+	lifetime       context.Context
+	service        services.CarService
+	batchLimit     int
+	importMaxBytes int64
 }
 
-func NewCarController(service services.CarService) *CarController {
-	return &CarController{service: service}
+// NewCarController builds the car handlers. Scrape jobs outlive the request
+// that started them but stop when lifetime is cancelled, at shutdown.
+// batchLimit caps the operations accepted by one batch request and
+// importMaxBytes the size of an import upload.
+func NewCarController(lifetime context.Context, service services.CarService, batchLimit int, importMaxBytes int64) *CarController {
+	return &CarController{lifetime: lifetime, service: service, batchLimit: batchLimit, importMaxBytes: importMaxBytes}
+}
+
+func parseCarID(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		return 0, apperror.Validation("invalid_id", "Invalid car ID", apperror.FieldError{
+			Field:   "id",
+			Code:    "invalid",
+			Message: "must be a positive integer",
+		})
+	}
+	return id, nil
+}
+
+// parseCarFilter reads the listing filters and sort from the query string.
+func parseCarFilter(c *gin.Context) (models.CarFilter, error) {
+	var filter models.CarFilter
+	var fields []apperror.FieldError
+	switch deleted := models.DeletedFilter(c.DefaultQuery("deleted", string(models.DeletedExclude))); deleted {
+	case models.DeletedExclude, models.DeletedInclude, models.DeletedOnly:
+		filter.Deleted = deleted
+	default:
+		fields = append(fields, apperror.FieldError{
+			Field:   "deleted",
+			Code:    "oneof",
+			Message: "must be one of exclude, include, only",
+		})
+	}
+	switch scams := models.ScamFilter(c.DefaultQuery("scams", string(models.ScamsExclude))); scams {
+	case models.ScamsExclude, models.ScamsInclude, models.ScamsOnly:
+		filter.Scams = scams
+	default:
+		fields = append(fields, apperror.FieldError{
+			Field:   "scams",
+			Code:    "oneof",
+			Message: "must be one of exclude, include, only",
+		})
+	}
+	switch sort := models.CarSort(c.DefaultQuery("sort", string(models.SortNewest))); sort {
+	case models.SortNewest, models.SortDealScore, models.SortDealPercentile:
+		filter.Sort = sort
+	default:
+		fields = append(fields, apperror.FieldError{
+			Field:   "sort",
+			Code:    "oneof",
+			Message: "must be one of newest, deal_score, deal_percentile",
+		})
+	}
+	if len(fields) > 0 {
+		return filter, apperror.Validation("invalid_filter", "Invalid listing filter", fields...)
+	}
+	return filter, nil
 }
 
 func (ctrl *CarController) GetCars(c *gin.Context) {
-	cars, err := ctrl.service.GetAllCars()
+	filter, err := parseCarFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cars, err := ctrl.service.GetAllCars(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -29,94 +96,233 @@ func (ctrl *CarController) GetCars(c *gin.Context) {
 }
 
 func (ctrl *CarController) GetCarByID(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
+	id, err := parseCarID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		c.Error(err)
 		return
 	}
 
-	car, err := ctrl.service.GetCarByID(int(id))
+	car, err := ctrl.service.GetCarByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	if car == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
+		c.Error(err)
 		return
 	}
 
+	setETag(c, car.Version)
 	c.JSON(http.StatusOK, car)
 }
 
 func (ctrl *CarController) CreateCar(c *gin.Context) {
-	var car models.Car
-	if err := c.ShouldBindJSON(&car); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req models.CarRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	if err := ctrl.service.CreateCar(&car); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	car := req.Car()
+	if err := ctrl.service.CreateCar(c.Request.Context(), &car); err != nil {
+		c.Error(err)
 		return
 	}
 
+	setETag(c, car.Version)
 	c.JSON(http.StatusCreated, car)
 }
 
+// UpdateCar replaces every editable field. An If-Match header makes the
+// write conditional on the car's current ETag.
 func (ctrl *CarController) UpdateCar(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
+	id, err := parseCarID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		c.Error(err)
 		return
 	}
 
-	var car models.Car
-	if err := c.ShouldBindJSON(&car); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req models.CarRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	car.ID = int(id)
-	if err := ctrl.service.UpdateCar(&car); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	car := req.Car()
+	car.ID = id
+	car.Version = version
+	if err := ctrl.service.UpdateCar(c.Request.Context(), &car); err != nil {
+		c.Error(err)
 		return
 	}
 
+	setETag(c, car.Version)
 	c.JSON(http.StatusOK, car)
 }
 
-func (ctrl *CarController) DeleteCar(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
+// PatchCar applies a JSON Merge Patch (RFC 7396) to the editable fields.
+// Without If-Match the patch is still applied against the version it was
+// merged with, so a concurrent write fails with 412 instead of being lost.
+func (ctrl *CarController) PatchCar(c *gin.Context) {
+	id, err := parseCarID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.Error(apperror.Validation("invalid_body", "Request body could not be read"))
+		return
+	}
+
+	current, err := ctrl.service.GetCarByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		c.Error(err)
+		return
+	}
+	if version != 0 && version != current.Version {
+		c.Error(apperror.PreconditionFailed("version_mismatch", "The car was modified by another request").
+			WithDetails(map[string]interface{}{
+				"expected_version": version,
+				"current_version":  current.Version,
+			}))
 		return
 	}
 
-	if err := ctrl.service.DeleteCar(int(id)); err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
-			return
+	stored := models.NewCarRequest(current)
+	var req models.CarRequest
+	if err := applyMergePatch(stored, patch, &req); err != nil {
+		c.Error(validation.Error(err))
+		return
+	}
+	if err := changedFieldErrors(validation.Struct(&req), stored, req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	car := req.Car()
+	car.ID = id
+	car.Version = current.Version
+	if err := ctrl.service.UpdateCar(c.Request.Context(), &car); err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, car.Version)
+	c.JSON(http.StatusOK, car)
+}
+
+// changedFieldErrors keeps only the validation errors on fields the patch
+// changed. Scraped rows are stored without the API's rules, so a value the
+// client did not touch is accepted as stored.
+func changedFieldErrors(err error, stored, merged models.CarRequest) error {
+	appErr := apperror.From(err)
+	if err == nil || appErr.Kind != apperror.KindValidation {
+		return err
+	}
+
+	before, after := carRequestFields(stored), carRequestFields(merged)
+	var changed []apperror.FieldError
+	for _, field := range appErr.Fields {
+		if before[field.Field] != after[field.Field] {
+			changed = append(changed, field)
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return apperror.Validation(appErr.Code, appErr.Message, changed...)
+}
+
+// carRequestFields maps the JSON name of each request field to its value.
+func carRequestFields(req models.CarRequest) map[string]string {
+	return map[string]string{
+		"title":    req.Title,
+		"price":    req.Price,
+		"currency": req.Currency,
+		"year":     req.Year,
+		"mileage":  req.Mileage,
+		"location": req.Location,
+		"link":     req.Link,
+		"seller":   req.Seller,
+	}
+}
+
+func (ctrl *CarController) DeleteCar(c *gin.Context) {
+	id, err := parseCarID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := ctrl.service.DeleteCar(c.Request.Context(), id); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Car deleted successfully"})
 }
 
+// RestoreCar takes a soft-deleted car out of the trash.
+func (ctrl *CarController) RestoreCar(c *gin.Context) {
+	id, err := parseCarID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	car, err := ctrl.service.RestoreCar(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, car.Version)
+	c.JSON(http.StatusOK, car)
+}
+
+// ScrapeCars runs a scrape job to completion. The job keeps the request's
+// trace and request ID but not its cancellation, so a client disconnecting
+// does not abandon it; shutting the server down does.
 func (ctrl *CarController) ScrapeCars(c *gin.Context) {
-	count, err := ctrl.service.ScrapeAndStoreCars()
+	ctx, cancel := context.WithCancel(context.WithoutCancel(c.Request.Context()))
+	defer cancel()
+	stop := context.AfterFunc(ctrl.lifetime, cancel)
+	defer stop()
+
+	result, err := ctrl.service.ScrapeAndStoreCars(ctx, nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Scraping finished with error: " + err.Error(),
-			"count": count,
-		})
+		scrapeErr := &apperror.Error{
+			Kind:    apperror.KindInternal,
+			Code:    "scrape_failed",
+			Message: "Scraping finished with an error",
+			Err:     err,
+		}
+		c.Error(scrapeErr.WithDetails(map[string]interface{}{
+			"job_id":   result.JobID,
+			"count":    result.Inserted,
+			"skipped":  result.Skipped,
+			"failed":   result.Failed,
+			"failures": result.Failures,
+		}))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Scraping completed successfully",
-		"count":   count,
+		"message":  "Scraping completed successfully",
+		"job_id":   result.JobID,
+		"count":    result.Inserted,
+		"skipped":  result.Skipped,
+		"failed":   result.Failed,
+		"failures": result.Failures,
 	})
 }
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/validation"
+	"github.com/gin-gonic/gin"
+)
+
+func TestChangedFieldErrors(t *testing.T) {
+	// A scraped row that breaks the create rules: the price has words in it
+	// and the year is out of range.
+	stored := models.CarRequest{
+		Title: "2015 Toyota Vios",
+		Price: "₱350,000 negotiable",
+		Year:  "1850",
+		Link:  "https://www.facebook.com/marketplace/item/1/",
+	}
+
+	tests := []struct {
+		name  string
+		patch func(req *models.CarRequest)
+		want  []string
+	}{
+		{name: "untouched invalid fields", patch: func(req *models.CarRequest) { req.Location = "Makati" }},
+		{name: "fixed field", patch: func(req *models.CarRequest) { req.Price = "₱340,000" }},
+		{name: "changed to invalid", patch: func(req *models.CarRequest) { req.Year = "3000" }, want: []string{"year"}},
+		{name: "cleared required", patch: func(req *models.CarRequest) { req.Title = "" }, want: []string{"title"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := stored
+			tt.patch(&merged)
+
+			err := changedFieldErrors(validation.Struct(&merged), stored, merged)
+			var got []string
+			if err != nil {
+				for _, field := range apperror.From(err).Fields {
+					got = append(got, field.Field)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("fields with errors = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("fields with errors = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCarFilterScams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query string
+		want  models.ScamFilter
+		err   bool
+	}{
+		{query: "", want: models.ScamsExclude},
+		{query: "?scams=exclude", want: models.ScamsExclude},
+		{query: "?scams=include", want: models.ScamsInclude},
+		{query: "?scams=only", want: models.ScamsOnly},
+		{query: "?scams=all", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/api/v1/cars"+tt.query, nil)
+
+			filter, err := parseCarFilter(c)
+			if tt.err {
+				if err == nil || apperror.From(err).Fields[0].Field != "scams" {
+					t.Errorf("err = %v, want a scams field error", err)
+				}
+				return
+			}
+			if err != nil || filter.Scams != tt.want {
+				t.Errorf("scams = %q, err = %v, want %q", filter.Scams, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+// Handlers pass the request's context to the service, so its deadline and
+// request ID reach the repository.
+func TestHandlersPassRequestContext(t *testing.T) {
+	service := &fakeCarService{cars: map[int]*models.Car{7: {ID: 7, Title: "2018 Toyota Vios", Version: 2}}}
+	router := newRouter(t, service)
+
+	recorder := serve(router, "GET", "/api/v1/cars/7", "", "X-Request-ID", "req-7")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body)
+	}
+	if len(service.requestIDs) != 1 || service.requestIDs[0] != "req-7" {
+		t.Errorf("service saw request IDs %q, want [req-7]", service.requestIDs)
+	}
+}
@@ -0,0 +1,51 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+func TestGetCarsSortedByDeal(t *testing.T) {
+	service := &fakeCarService{cars: map[int]*models.Car{
+		1: {ID: 1, Title: "2016 Honda City"},
+		2: {ID: 2, Title: "2018 Toyota Vios", Deal: &models.DealScore{FairPrice: 500000, Comparables: 6, Score: 0.1, Percentile: 0.8}},
+	}}
+	router := newRouter(t, service)
+
+	for _, sort := range []models.CarSort{models.SortDealScore, models.SortDealPercentile} {
+		recorder := serve(router, "GET", "/api/v1/cars?sort="+string(sort), "")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("sort=%s: status = %d, body %s", sort, recorder.Code, recorder.Body)
+		}
+		if service.filter.Sort != sort {
+			t.Errorf("sort=%s: service sorted by %q", sort, service.filter.Sort)
+		}
+	}
+	serve(router, "GET", "/api/v1/cars", "")
+	if service.filter.Sort != models.SortNewest {
+		t.Errorf("default sort = %q, want newest", service.filter.Sort)
+	}
+
+	// Scored listings carry their deal; unscored ones have none.
+	var cars []map[string]json.RawMessage
+	if err := json.Unmarshal(serve(router, "GET", "/api/v1/cars", "").Body.Bytes(), &cars); err != nil {
+		t.Fatal(err)
+	}
+	if len(cars) != 2 {
+		t.Fatalf("listed %d cars, want 2", len(cars))
+	}
+	if _, ok := cars[0]["deal"]; ok {
+		t.Errorf("unscored car has a deal: %s", cars[0]["deal"])
+	}
+	var deal models.DealScore
+	if err := json.Unmarshal(cars[1]["deal"], &deal); err != nil || deal.Comparables != 6 || deal.Percentile != 0.8 {
+		t.Errorf("deal = %s (%v), want the stored score", cars[1]["deal"], err)
+	}
+
+	if code := serve(router, "GET", "/api/v1/cars?sort=cheapest", "").Code; code != http.StatusBadRequest {
+		t.Errorf("sort=cheapest status = %d, want 400", code)
+	}
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+// setETag tags the response with the car version it represents.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatchVersion returns the version named by the If-Match header, or 0 when
+// the header is absent or "*" and any version may be overwritten.
+func ifMatchVersion(c *gin.Context) (int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err == nil {
+		var version int
+		if version, err = strconv.Atoi(unquoted); err == nil && version > 0 {
+			return version, nil
+		}
+	}
+	return 0, apperror.Validation("invalid_if_match", "If-Match must be a single ETag returned by this API", apperror.FieldError{
+		Field:   "If-Match",
+		Code:    "invalid",
+		Message: `must look like "3"`,
+	})
+}
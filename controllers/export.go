@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/export"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportCars streams the listings matching the list filters as CSV, NDJSON
+// or XLSX. Errors before the first row is sent get a problem response; after
+// that the download can only be cut short, and the error is logged.
+func (ctrl *CarController) ExportCars(c *gin.Context) {
+	filter, err := parseCarFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	format, err := export.ParseFormat(c.DefaultQuery("format", string(export.CSV)))
+	if err != nil {
+		names := make([]string, len(export.Formats))
+		for i, f := range export.Formats {
+			names[i] = string(f)
+		}
+		c.Error(apperror.Validation("invalid_format", "Unsupported export format", apperror.FieldError{
+			Field:   "format",
+			Code:    "oneof",
+			Message: "must be one of " + strings.Join(names, ", "),
+		}))
+		return
+	}
+
+	filename := fmt.Sprintf("cars-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	count, err := ctrl.service.ExportCars(c.Request.Context(), filter, format, c.Writer)
+	if err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.Error(err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "export aborted", "format", format, "rows", count, "error", err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,55 @@
+package controllers_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/models"
+)
+
+func TestExportCars(t *testing.T) {
+	service := &fakeCarService{cars: map[int]*models.Car{
+		1: {ID: 1, Title: "2016 Honda City", Version: 1},
+		2: {ID: 2, Title: "2018 Toyota Vios", Version: 1},
+	}}
+	router := newRouter(t, service)
+
+	recorder := serve(router, "GET", "/api/v1/cars/export?deleted=include", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("content type = %q, want CSV by default", got)
+	}
+	if got := recorder.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="cars-`) || !strings.HasSuffix(got, `.csv"`) {
+		t.Errorf("content disposition = %q", got)
+	}
+	records, err := csv.NewReader(recorder.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[1][1] != "2016 Honda City" {
+		t.Errorf("records = %q, want a header and both cars", records)
+	}
+	if service.filter.Deleted != models.DeletedInclude {
+		t.Errorf("filter = %+v, want deleted listings included", service.filter)
+	}
+
+	if got := serve(router, "GET", "/api/v1/cars/export?format=ndjson", "").Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("ndjson content type = %q", got)
+	}
+	if code := serve(router, "GET", "/api/v1/cars/export?format=pdf", "").Code; code != http.StatusBadRequest {
+		t.Errorf("format=pdf status = %d, want 400", code)
+	}
+
+	// A failure before the first row is still a problem response.
+	service.exportErr = apperror.Unavailable("database_unavailable", "The database is temporarily unavailable", errors.New("timeout"))
+	recorder = serve(router, "GET", "/api/v1/cars/export", "")
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Header().Get("Content-Disposition") != "" {
+		t.Errorf("status = %d, disposition %q, want 503 without an attachment", recorder.Code, recorder.Header().Get("Content-Disposition"))
+	}
+}
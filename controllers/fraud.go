@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/fraud"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultFraudCaseLimit = 100
+	maxFraudCaseLimit     = 1000
+)
+
+// GetFraudCases lists flagged listings in the review state given by
+// ?status=, pending by default, whose score reaches ?min_score=.
+func (ctrl *CarController) GetFraudCases(c *gin.Context) {
+	query, err := parseFraudCaseQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cases, err := ctrl.service.FraudCases(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if cases == nil {
+		cases = []models.FraudCase{}
+	}
+	c.JSON(http.StatusOK, cases)
+}
+
+// ConfirmFraud marks a listing as a confirmed scam, hiding it from listing
+// queries.
+func (ctrl *CarController) ConfirmFraud(c *gin.Context) {
+	ctrl.reviewFraud(c, models.FraudConfirmed)
+}
+
+// DismissFraud marks a listing's flags as false positives.
+func (ctrl *CarController) DismissFraud(c *gin.Context) {
+	ctrl.reviewFraud(c, models.FraudDismissed)
+}
+
+// reviewFraud records status for the listing in the path, with the note
+// from the optional JSON body.
+func (ctrl *CarController) reviewFraud(c *gin.Context, status models.FraudReviewStatus) {
+	id, err := parseCarID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req models.FraudReviewRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	review, err := ctrl.service.ReviewFraud(c.Request.Context(), id, status, req.Note)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+func parseFraudCaseQuery(c *gin.Context) (models.FraudCaseQuery, error) {
+	query := models.FraudCaseQuery{
+		Status:   models.FraudReviewStatus(c.DefaultQuery("status", string(models.FraudPending))),
+		MinScore: fraud.ReviewThreshold,
+		Limit:    defaultFraudCaseLimit,
+	}
+	var fields []apperror.FieldError
+
+	switch query.Status {
+	case models.FraudPending, models.FraudConfirmed, models.FraudDismissed:
+	default:
+		fields = append(fields, apperror.FieldError{
+			Field:   "status",
+			Code:    "oneof",
+			Message: "must be one of pending, confirmed, dismissed",
+		})
+	}
+	if raw := c.Query("min_score"); raw != "" {
+		score, err := strconv.ParseFloat(raw, 64)
+		if err != nil || score < 0 {
+			fields = append(fields, apperror.FieldError{Field: "min_score", Code: "min", Message: "must be a number of at least 0"})
+		} else {
+			query.MinScore = score
+		}
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxFraudCaseLimit {
+			fields = append(fields, apperror.FieldError{Field: "limit", Code: "range", Message: fmt.Sprintf("must be between 1 and %d", maxFraudCaseLimit)})
+		} else {
+			query.Limit = limit
+		}
+	}
+
+	if len(fields) > 0 {
+		return query, apperror.Validation("invalid_query", "Invalid fraud case query", fields...)
+	}
+	return query, nil
+}
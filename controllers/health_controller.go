@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/migrations"
+	"github.com/yourusername/car-listing-service/services"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	db            *sql.DB
+	migrator      *migrations.Migrator
+	service       services.CarService
+	scraperConfig config.ScraperConfig
+	timeout       time.Duration
+}
+
+func NewHealthController(db *sql.DB, migrator *migrations.Migrator, service services.CarService, cfg *config.Config) *HealthController {
+	return &HealthController{
+		db:            db,
+		migrator:      migrator,
+		service:       service,
+		scraperConfig: cfg.Scraper,
+		timeout:       cfg.ReadinessTimeout,
+	}
+}
+
+type healthCheck struct {
+	Status  string      `json:"status"`
+	Latency string      `json:"latency,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Livez reports that the process is up and serving requests.
+func (ctrl *HealthController) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"service": "car-listing-service",
+	})
+}
+
+// Readyz checks the database, migrations and scraper, answering 503 when the
+// database or migrations fail. Only scraping needs the browser, so a missing
+// one is reported as a warning and the API stays in rotation. Error details
+// go to the log rather than the public response.
+func (ctrl *HealthController) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), ctrl.timeout)
+	defer cancel()
+
+	checks := map[string]healthCheck{
+		"database":   ctrl.checkDatabase(ctx),
+		"migrations": ctrl.checkMigrations(ctx),
+		"scraper":    ctrl.checkScraper(ctx),
+	}
+
+	status, code := "ok", http.StatusOK
+	for _, check := range checks {
+		if check.Status == "fail" {
+			status, code = "degraded", http.StatusServiceUnavailable
+		}
+	}
+
+	c.JSON(code, gin.H{
+		"status":  status,
+		"service": "car-listing-service",
+		"checks":  checks,
+	})
+}
+
+func (ctrl *HealthController) checkDatabase(ctx context.Context) healthCheck {
+	start := time.Now()
+	err := ctrl.db.PingContext(ctx)
+	check := healthCheck{Status: "pass", Latency: time.Since(start).String()}
+	if err != nil {
+		slog.WarnContext(ctx, "readiness: database ping failed", "error", err)
+		check.Status = "fail"
+		check.Error = "database unreachable"
+	}
+	return check
+}
+
+func (ctrl *HealthController) checkMigrations(ctx context.Context) healthCheck {
+	pending, err := ctrl.migrator.Pending(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "readiness: reading migration state failed", "error", err)
+		return healthCheck{Status: "fail", Error: "could not read migration state"}
+	}
+
+	details := gin.H{"latest": ctrl.migrator.Latest(), "pending": len(pending)}
+	if len(pending) > 0 {
+		return healthCheck{
+			Status:  "fail",
+			Error:   fmt.Sprintf("%d migrations pending, first is %d_%s", len(pending), pending[0].Version, pending[0].Name),
+			Details: details,
+		}
+	}
+	return healthCheck{Status: "pass", Details: details}
+}
+
+// checkScraper never fails readiness: it warns when the browser is missing.
+// The last scrape error is logged when the job fails, not repeated here.
+func (ctrl *HealthController) checkScraper(ctx context.Context) healthCheck {
+	status := ctrl.service.ScrapeStatus()
+	details := gin.H{
+		"running":         status.Running,
+		"last_success_at": status.LastSuccessAt,
+	}
+	if status.LastFailureAt != nil {
+		details["last_failure_at"] = status.LastFailureAt
+	}
+
+	// Replays never launch a browser.
+	if ctrl.scraperConfig.ReplayDir != "" {
+		details["browser"] = "not required (replay mode)"
+		return healthCheck{Status: "pass", Details: details}
+	}
+
+	if _, err := services.BrowserPath(); err != nil {
+		slog.WarnContext(ctx, "readiness: no browser for scraping", "error", err)
+		details["browser"] = "missing"
+		return healthCheck{Status: "warn", Error: "no Chrome or Chromium binary found; scraping is unavailable", Details: details}
+	}
+	details["browser"] = "available"
+	return healthCheck{Status: "pass", Details: details}
+}
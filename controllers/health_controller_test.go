@@ -0,0 +1,76 @@
+package controllers_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/controllers"
+	"github.com/yourusername/car-listing-service/migrations"
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+)
+
+func TestReadyzWithoutDatabase(t *testing.T) {
+	db, err := sql.Open("postgres", "postgres://postgres@127.0.0.1:1/car_listing?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lastSuccess := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	service := &fakeCarService{}
+	service.status.LastSuccessAt = &lastSuccess
+	health := controllers.NewHealthController(db, migrator, service, &config.Config{
+		ReadinessTimeout: time.Second,
+		Scraper:          config.ScraperConfig{ReplayDir: t.TempDir()},
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/livez", health.Livez)
+	router.GET("/readyz", health.Readyz)
+
+	if code := serve(router, "GET", "/livez", "").Code; code != http.StatusOK {
+		t.Errorf("livez status = %d, want 200", code)
+	}
+
+	recorder := serve(router, "GET", "/readyz", "")
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("readyz status = %d, want 503", recorder.Code)
+	}
+	var body struct {
+		Status string `json:"status"`
+		Checks map[string]struct {
+			Status  string                 `json:"status"`
+			Error   string                 `json:"error"`
+			Details map[string]interface{} `json:"details"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != "degraded" {
+		t.Errorf("status = %q, want degraded", body.Status)
+	}
+	for name, want := range map[string]string{"database": "fail", "migrations": "fail", "scraper": "pass"} {
+		if got := body.Checks[name].Status; got != want {
+			t.Errorf("%s check = %q, want %q", name, got, want)
+		}
+	}
+	if got := body.Checks["scraper"].Details["last_success_at"]; got != "2026-03-04T05:06:07Z" {
+		t.Errorf("scraper last_success_at = %v", got)
+	}
+	// The driver's message stays in the log.
+	if strings.Contains(recorder.Body.String(), "refused") {
+		t.Errorf("readyz leaks the connection error: %s", recorder.Body.String())
+	}
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/importer"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportCars stores the listings in a CSV or NDJSON upload and reports what
+// happened to every line. The file is either the "file" part of a multipart
+// form or the raw request body. With ?dry_run=true nothing is stored.
+func (ctrl *CarController) ImportCars(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.Error(apperror.Validation("invalid_query", "Invalid import options", apperror.FieldError{
+			Field:   "dry_run",
+			Code:    "boolean",
+			Message: "must be true or false",
+		}))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, ctrl.importMaxBytes)
+
+	body, filename, err := importUpload(c)
+	if err != nil {
+		c.Error(importReadError(err, ctrl.importMaxBytes))
+		return
+	}
+
+	format, err := importFormat(c, filename)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := ctrl.service.ImportListings(c.Request.Context(), format, body, dryRun)
+	if err != nil {
+		c.Error(importReadError(err, ctrl.importMaxBytes))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// importUpload returns the uploaded file and its name. For a multipart form
+// it streams the "file" part rather than spooling the form to disk.
+func importUpload(c *gin.Context) (io.Reader, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if mediaType != "multipart/form-data" {
+		return c.Request.Body, "", nil
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, "", apperror.Validation("invalid_body", "The multipart form could not be read")
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, "", apperror.Validation("missing_file", "No file uploaded", apperror.FieldError{
+				Field:   "file",
+				Code:    "required",
+				Message: "is required",
+			})
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, "", err
+		}
+		if err != nil {
+			return nil, "", apperror.Validation("invalid_body", "The multipart form could not be read")
+		}
+		if part.FormName() == "file" {
+			return part, part.FileName(), nil
+		}
+	}
+}
+
+// importFormat picks the file format from ?format=, then the uploaded file's
+// extension, then the request's Content-Type.
+func importFormat(c *gin.Context, filename string) (importer.Format, error) {
+	name := c.Query("format")
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Ext(filename), ".")
+	}
+	if name == "" {
+		switch c.ContentType() {
+		case "text/csv":
+			name = string(importer.CSV)
+		case "application/x-ndjson", "application/ndjson", "application/jsonl":
+			name = string(importer.NDJSON)
+		}
+	}
+
+	format, err := importer.ParseFormat(name)
+	if err != nil {
+		return "", apperror.Validation("invalid_format", "Unsupported import format", apperror.FieldError{
+			Field:   "format",
+			Code:    "oneof",
+			Message: "must be one of csv, ndjson",
+		})
+	}
+	return format, nil
+}
+
+// importReadError reports an upload over the size limit as a validation
+// error and passes anything else through.
+func importReadError(err error, maxBytes int64) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apperror.Validation("file_too_large", "The uploaded file is too large", apperror.FieldError{
+			Field:   "file",
+			Code:    "max",
+			Message: fmt.Sprintf("must be at most %d bytes", maxBytes),
+		})
+	}
+	return err
+}
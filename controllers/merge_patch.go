@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"encoding/json"
+)
+
+// applyMergePatch applies an RFC 7396 JSON Merge Patch to the JSON encoding
+// of target and decodes the result into out.
+func applyMergePatch(target interface{}, patch []byte, out interface{}) error {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return err
+	}
+
+	original, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(original, &doc); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergePatch(doc, patchDoc))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(merged, out)
+}
+
+// mergePatch implements the MergePatch algorithm from RFC 7396 section 2:
+// objects merge recursively, null removes a member and anything else
+// replaces the target outright.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = mergePatch(targetObj[name], value)
+	}
+	return targetObj
+}
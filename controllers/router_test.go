@@ -0,0 +1,213 @@
+package controllers_test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/controllers"
+	"github.com/yourusername/car-listing-service/export"
+	"github.com/yourusername/car-listing-service/logging"
+	"github.com/yourusername/car-listing-service/middleware"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/routes"
+	"github.com/yourusername/car-listing-service/services"
+	"github.com/yourusername/car-listing-service/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeCarService stands in for the database-backed service. Methods a test
+// does not expect panic through the nil embedded interface.
+type fakeCarService struct {
+	services.CarService
+	status services.ScrapeStatus
+	cars   map[int]*models.Car
+	// filter is the last filter GetAllCars was called with.
+	filter models.CarFilter
+	exportErr error
+	// statsQuery is the last query GetStats was called with; groups is
+	// its answer.
+	statsQuery models.StatsQuery
+	groups     []models.StatsGroup
+	trendQuery models.TrendQuery
+	points     []models.TrendPoint
+	// batches holds the operations of each ApplyBatch call.
+	batches [][]models.BatchOperation
+	// requestIDs holds the request ID carried by the context of each call.
+	requestIDs []string
+}
+
+func (s *fakeCarService) called(ctx context.Context) {
+	s.requestIDs = append(s.requestIDs, logging.RequestID(ctx))
+}
+
+func (s *fakeCarService) GetCarByID(ctx context.Context, id int) (*models.Car, error) {
+	s.called(ctx)
+	car, ok := s.cars[id]
+	if !ok || car.DeletedAt != nil {
+		return nil, apperror.NotFound("car_not_found", "Car not found")
+	}
+	copied := *car
+	return &copied, nil
+}
+
+func (s *fakeCarService) ScrapeStatus() services.ScrapeStatus {
+	return s.status
+}
+
+func (s *fakeCarService) GetAllCars(ctx context.Context, filter models.CarFilter) ([]models.Car, error) {
+	s.called(ctx)
+	s.filter = filter
+	cars := []models.Car{}
+	for id := 1; id <= len(s.cars); id++ {
+		car, ok := s.cars[id]
+		if !ok {
+			continue
+		}
+		deleted := car.DeletedAt != nil
+		switch filter.Deleted {
+		case models.DeletedExclude:
+			if deleted {
+				continue
+			}
+		case models.DeletedOnly:
+			if !deleted {
+				continue
+			}
+		}
+		cars = append(cars, *car)
+	}
+	return cars, nil
+}
+
+func (s *fakeCarService) DeleteCar(ctx context.Context, id int) error {
+	s.called(ctx)
+	car, ok := s.cars[id]
+	if !ok || car.DeletedAt != nil {
+		return apperror.NotFound("car_not_found", "Car not found")
+	}
+	now := time.Now()
+	car.DeletedAt = &now
+	car.Version++
+	return nil
+}
+
+func (s *fakeCarService) RestoreCar(ctx context.Context, id int) (*models.Car, error) {
+	s.called(ctx)
+	car, ok := s.cars[id]
+	if !ok || car.DeletedAt == nil {
+		return nil, apperror.NotFound("car_not_found", "Car not found")
+	}
+	car.DeletedAt = nil
+	car.Version++
+	copied := *car
+	return &copied, nil
+}
+
+// ApplyBatch creates, updates and deletes in s.cars. An atomic batch stops
+// at the first failure, as the real one rolls back there.
+func (s *fakeCarService) ApplyBatch(ctx context.Context, mode models.BatchMode, ops []models.BatchOperation) ([]services.BatchOutcome, error) {
+	s.called(ctx)
+	s.batches = append(s.batches, ops)
+	outcomes := make([]services.BatchOutcome, len(ops))
+	for i, op := range ops {
+		switch op.Op {
+		case models.BatchCreate:
+			car := op.Car.Car()
+			car.ID, car.Version = len(s.cars)+1, 1
+			s.cars[car.ID] = &car
+			outcomes[i].Car = &car
+		case models.BatchUpdate:
+			stored, err := s.GetCarByID(ctx, op.ID)
+			if err == nil {
+				car := op.Car.Car()
+				car.ID, car.Version = op.ID, stored.Version+1
+				s.cars[car.ID] = &car
+				outcomes[i].Car = &car
+			}
+			outcomes[i].Err = err
+		case models.BatchDelete:
+			outcomes[i].Err = s.DeleteCar(ctx, op.ID)
+		}
+		if outcomes[i].Err != nil && mode == models.BatchAtomic {
+			return nil, &services.BatchOpError{Index: i, Op: op.Op, Err: outcomes[i].Err}
+		}
+	}
+	return outcomes, nil
+}
+
+// ExportCars writes s.cars in ID order, or fails with s.exportErr before
+// writing anything.
+func (s *fakeCarService) ExportCars(ctx context.Context, filter models.CarFilter, format export.Format, w io.Writer) (int, error) {
+	s.called(ctx)
+	s.filter = filter
+	if s.exportErr != nil {
+		return 0, s.exportErr
+	}
+	writer, err := export.NewWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for id := 1; id <= len(s.cars); id++ {
+		if car, ok := s.cars[id]; ok {
+			if err := writer.Write(car); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, writer.Close()
+}
+
+func (s *fakeCarService) GetStats(ctx context.Context, query models.StatsQuery) ([]models.StatsGroup, error) {
+	s.called(ctx)
+	s.statsQuery = query
+	return s.groups, nil
+}
+
+func (s *fakeCarService) GetPriceTrends(ctx context.Context, query models.TrendQuery) ([]models.TrendPoint, error) {
+	s.called(ctx)
+	s.trendQuery = query
+	return s.points, nil
+}
+
+// newRouter serves the API routes over service with the middleware that
+// shapes responses: request IDs and problem+json errors.
+func newRouter(t *testing.T, service services.CarService) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	if err := validation.Register(); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Recovery())
+	router.Use(middleware.ErrorHandler())
+	router.NoRoute(middleware.NoRoute)
+	routes.SetupRoutes(router, controllers.NewCarController(context.Background(), service, 10, 1<<20))
+	return router
+}
+
+// serve sends a request with an optional body and returns the response.
+func serve(handler *gin.Engine, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
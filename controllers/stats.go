@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/validation"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultStatsLimit = 100
+	maxStatsLimit     = 1000
+)
+
+// GetStats returns price and mileage statistics for live listings, grouped
+// by the dimensions in ?group_by=.
+func (ctrl *CarController) GetStats(c *gin.Context) {
+	query, err := parseStatsQuery(c, time.Now())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	groups, err := ctrl.service.GetStats(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if groups == nil {
+		groups = []models.StatsGroup{}
+	}
+
+	groupBy := query.GroupBy
+	if groupBy == nil {
+		groupBy = []models.StatsDimension{}
+	}
+	c.JSON(http.StatusOK, gin.H{"group_by": groupBy, "currency": query.Currency, "groups": groups})
+}
+
+// GetPriceTrends returns the median asking price and listing volume of one
+// make, and optionally model and year range, per week or month.
+func (ctrl *CarController) GetPriceTrends(c *gin.Context) {
+	query, err := parseTrendQuery(c, time.Now())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	points, err := ctrl.service.GetPriceTrends(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if points == nil {
+		points = []models.TrendPoint{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"interval": query.Interval,
+		"make":     strings.ToLower(query.Make),
+		"model":    strings.ToLower(query.Model),
+		"currency": query.Currency,
+		"points":   points,
+	})
+}
+
+func parseStatsQuery(c *gin.Context, now time.Time) (models.StatsQuery, error) {
+	p := queryParser{c: c, now: now}
+	query := models.StatsQuery{
+		Make:     c.Query("make"),
+		Model:    c.Query("model"),
+		Location: c.Query("location"),
+		Currency: p.currency(),
+		YearMin:  p.year("year_min"),
+		YearMax:  p.year("year_max"),
+		Since:    p.since(),
+		Until:    p.time("until"),
+		Limit:    defaultStatsLimit,
+	}
+
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		seen := make(map[models.StatsDimension]bool)
+		for _, name := range strings.Split(groupBy, ",") {
+			dim := models.StatsDimension(strings.TrimSpace(name))
+			if !validStatsDimension(dim) {
+				p.invalid("group_by", "oneof", "must be a comma-separated list of make, model, year, location")
+				break
+			}
+			if !seen[dim] {
+				seen[dim] = true
+				query.GroupBy = append(query.GroupBy, dim)
+			}
+		}
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxStatsLimit {
+			p.invalid("limit", "range", fmt.Sprintf("must be between 1 and %d", maxStatsLimit))
+		} else {
+			query.Limit = limit
+		}
+	}
+
+	return query, p.err("Invalid statistics query")
+}
+
+func parseTrendQuery(c *gin.Context, now time.Time) (models.TrendQuery, error) {
+	p := queryParser{c: c, now: now}
+	query := models.TrendQuery{
+		Interval: models.TrendInterval(c.DefaultQuery("interval", string(models.TrendWeekly))),
+		Make:     c.Query("make"),
+		Model:    c.Query("model"),
+		Currency: p.currency(),
+		YearMin:  p.year("year_min"),
+		YearMax:  p.year("year_max"),
+		Since:    p.since(),
+		Until:    p.time("until"),
+	}
+
+	if query.Interval != models.TrendWeekly && query.Interval != models.TrendMonthly {
+		p.invalid("interval", "oneof", "must be one of week, month")
+	}
+	if query.Make == "" {
+		p.invalid("make", "required", "is required")
+	}
+
+	return query, p.err("Invalid price trend query")
+}
+
+func validStatsDimension(dim models.StatsDimension) bool {
+	for _, known := range models.StatsDimensions {
+		if dim == known {
+			return true
+		}
+	}
+	return false
+}
+
+// queryParser reads the filters shared by the statistics endpoints,
+// collecting every invalid parameter so they are reported at once.
+type queryParser struct {
+	c      *gin.Context
+	now    time.Time
+	fields []apperror.FieldError
+}
+
+func (p *queryParser) invalid(field, code, message string) {
+	p.fields = append(p.fields, apperror.FieldError{Field: field, Code: code, Message: message})
+}
+
+func (p *queryParser) err(message string) error {
+	if len(p.fields) == 0 {
+		return nil
+	}
+	return apperror.Validation("invalid_query", message, p.fields...)
+}
+
+// currency defaults to PHP, so prices in different currencies are never
+// aggregated together.
+func (p *queryParser) currency() string {
+	currency := strings.ToUpper(p.c.DefaultQuery("currency", "PHP"))
+	if currency != "PHP" && currency != "USD" {
+		p.invalid("currency", "oneof", "must be one of PHP, USD")
+	}
+	return currency
+}
+
+func (p *queryParser) year(field string) int {
+	raw := p.c.Query(field)
+	if raw == "" {
+		return 0
+	}
+	year, err := strconv.Atoi(raw)
+	if err != nil || year < validation.MinCarYear || year > p.now.Year()+1 {
+		p.invalid(field, "car_year", fmt.Sprintf("must be a year between %d and %d", validation.MinCarYear, p.now.Year()+1))
+		return 0
+	}
+	return year
+}
+
+// time reads an RFC 3339 timestamp or a YYYY-MM-DD date.
+func (p *queryParser) time(field string) *time.Time {
+	raw := p.c.Query(field)
+	if raw == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", raw); err != nil {
+			p.invalid(field, "datetime", "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return nil
+		}
+	}
+	return &t
+}
+
+// since reads ?since=, or ?window= as a shorthand for since=now-window.
+func (p *queryParser) since() *time.Time {
+	since := p.time("since")
+	raw := p.c.Query("window")
+	if raw == "" {
+		return since
+	}
+
+	window, err := parseWindow(raw)
+	switch {
+	case err != nil || window <= 0:
+		p.invalid("window", "duration", "must be a positive duration such as 30d, 12w or 72h")
+	case since != nil:
+		p.invalid("window", "excluded_with", "cannot be combined with since")
+	default:
+		start := p.now.Add(-window)
+		return &start
+	}
+	return since
+}
+
+// parseWindow accepts Go durations plus whole days ("30d") and weeks ("12w").
+func parseWindow(raw string) (time.Duration, error) {
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[raw[len(raw)-1]]; ok {
+		n, err := strconv.Atoi(raw[:len(raw)-1])
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * unit, nil
+	}
+	return time.ParseDuration(raw)
+}
@@ -0,0 +1,129 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/middleware"
+	"github.com/yourusername/car-listing-service/models"
+)
+
+// invalidFields returns the fields of a validation problem, sorted.
+func invalidFields(t *testing.T, body []byte) []string {
+	t.Helper()
+	var problem middleware.Problem
+	if err := json.Unmarshal(body, &problem); err != nil {
+		t.Fatal(err)
+	}
+	var fields []string
+	for _, field := range problem.Errors {
+		fields = append(fields, field.Field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func TestGetStats(t *testing.T) {
+	service := &fakeCarService{groups: []models.StatsGroup{{Make: "toyota", Count: 3}}}
+	router := newRouter(t, service)
+
+	recorder := serve(router, "GET", "/api/v1/stats?group_by=make,model,make&make=Toyota&year_min=2015&since=2026-01-01&limit=5", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body)
+	}
+	query := service.statsQuery
+	if len(query.GroupBy) != 2 || query.GroupBy[0] != models.StatsDimension("make") || query.GroupBy[1] != models.StatsDimension("model") {
+		t.Errorf("group by = %v, want make and model once each", query.GroupBy)
+	}
+	if query.Make != "Toyota" || query.YearMin != 2015 || query.Currency != "PHP" || query.Limit != 5 {
+		t.Errorf("query = %+v", query)
+	}
+	if query.Since == nil || !query.Since.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("since = %v, want 2026-01-01", query.Since)
+	}
+
+	var body struct {
+		GroupBy  []string            `json:"group_by"`
+		Currency string              `json:"currency"`
+		Groups   []models.StatsGroup `json:"groups"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.GroupBy) != 2 || body.Currency != "PHP" || len(body.Groups) != 1 || body.Groups[0].Count != 3 {
+		t.Errorf("body = %s", recorder.Body)
+	}
+
+	// A window is shorthand for since.
+	before := time.Now().Add(-30 * 24 * time.Hour)
+	serve(router, "GET", "/api/v1/stats?window=30d", "")
+	after := time.Now().Add(-30 * 24 * time.Hour)
+	if since := service.statsQuery.Since; since == nil || since.Before(before) || since.After(after) {
+		t.Errorf("window=30d: since = %v, want 30 days ago", since)
+	}
+}
+
+func TestGetStatsInvalidQuery(t *testing.T) {
+	router := newRouter(t, &fakeCarService{})
+	// Every invalid parameter is reported at once.
+	path := fmt.Sprintf("/api/v1/stats?group_by=colour&currency=EUR&year_min=1800&year_max=%d&until=yesterday&since=2026-01-01&window=2w&limit=0",
+		time.Now().Year()+2)
+	recorder := serve(router, "GET", path, "")
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", recorder.Code)
+	}
+	want := []string{"currency", "group_by", "limit", "until", "window", "year_max", "year_min"}
+	if got := invalidFields(t, recorder.Body.Bytes()); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("invalid fields = %v, want %v", got, want)
+	}
+}
+
+func TestGetPriceTrends(t *testing.T) {
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	service := &fakeCarService{points: []models.TrendPoint{{PeriodStart: march, Listings: 4, NewListings: 3, MedianPrice: 450000}}}
+	router := newRouter(t, service)
+
+	recorder := serve(router, "GET", "/api/v1/stats/trends?make=Toyota&model=Vios&interval=month&currency=usd&year_max=2020", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body)
+	}
+	query := service.trendQuery
+	if query.Interval != models.TrendMonthly || query.Make != "Toyota" || query.Model != "Vios" || query.Currency != "USD" || query.YearMax != 2020 {
+		t.Errorf("query = %+v", query)
+	}
+
+	var body struct {
+		Interval string              `json:"interval"`
+		Make     string              `json:"make"`
+		Model    string              `json:"model"`
+		Currency string              `json:"currency"`
+		Points   []models.TrendPoint `json:"points"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Interval != "month" || body.Make != "toyota" || body.Model != "vios" || body.Currency != "USD" {
+		t.Errorf("body = %s", recorder.Body)
+	}
+	if len(body.Points) != 1 || !body.Points[0].PeriodStart.Equal(march) || body.Points[0].MedianPrice != 450000 {
+		t.Errorf("points = %+v", body.Points)
+	}
+
+	// Weekly is the default interval.
+	serve(router, "GET", "/api/v1/stats/trends?make=honda", "")
+	if service.trendQuery.Interval != models.TrendWeekly {
+		t.Errorf("default interval = %q, want week", service.trendQuery.Interval)
+	}
+
+	recorder = serve(router, "GET", "/api/v1/stats/trends?interval=day", "")
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("invalid query status = %d, want 400", recorder.Code)
+	}
+	if got := invalidFields(t, recorder.Body.Bytes()); fmt.Sprint(got) != "[interval make]" {
+		t.Errorf("invalid fields = %v, want interval and make", got)
+	}
+}
@@ -0,0 +1,70 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+func TestDeleteAndRestoreCar(t *testing.T) {
+	service := &fakeCarService{cars: map[int]*models.Car{
+		1: {ID: 1, Title: "2018 Toyota Vios", Version: 1},
+		2: {ID: 2, Title: "2016 Honda City", Version: 1},
+	}}
+	router := newRouter(t, service)
+
+	listed := func(query string) []int {
+		t.Helper()
+		recorder := serve(router, "GET", "/api/v1/cars"+query, "")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET /api/v1/cars%s: status %d, body %s", query, recorder.Code, recorder.Body)
+		}
+		var cars []models.Car
+		if err := json.Unmarshal(recorder.Body.Bytes(), &cars); err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, car := range cars {
+			ids = append(ids, car.ID)
+		}
+		return ids
+	}
+
+	if code := serve(router, "DELETE", "/api/v1/cars/1", "").Code; code != http.StatusOK {
+		t.Fatalf("delete status = %d, want 200", code)
+	}
+	if code := serve(router, "DELETE", "/api/v1/cars/1", "").Code; code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want 404", code)
+	}
+	if code := serve(router, "GET", "/api/v1/cars/1", "").Code; code != http.StatusNotFound {
+		t.Errorf("get deleted status = %d, want 404", code)
+	}
+	if ids := listed(""); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("live cars = %v, want [2]", ids)
+	}
+	if ids := listed("?deleted=only"); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("trashed cars = %v, want [1]", ids)
+	}
+	if ids := listed("?deleted=include"); len(ids) != 2 {
+		t.Errorf("all cars = %v, want both", ids)
+	}
+	if code := serve(router, "GET", "/api/v1/cars?deleted=all", "").Code; code != http.StatusBadRequest {
+		t.Errorf("deleted=all status = %d, want 400", code)
+	}
+
+	recorder := serve(router, "POST", "/api/v1/cars/1/restore", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("restore status = %d, body %s", recorder.Code, recorder.Body)
+	}
+	if etag := recorder.Header().Get("ETag"); etag != `"3"` {
+		t.Errorf("restore ETag = %s, want \"3\"", etag)
+	}
+	if code := serve(router, "POST", "/api/v1/cars/2/restore", "").Code; code != http.StatusNotFound {
+		t.Errorf("restoring a live car: status = %d, want 404", code)
+	}
+	if code := serve(router, "POST", "/api/v1/cars/abc/restore", "").Code; code != http.StatusBadRequest {
+		t.Errorf("restoring a bad ID: status = %d, want 400", code)
+	}
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Valuate predicts the asking price of the car in the body with the newest
+// trained model.
+func (ctrl *CarController) Valuate(c *gin.Context) {
+	var req models.ValuationRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := ctrl.service.Valuate(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetValuationModels lists the trained model versions and their held-out
+// metrics, newest first.
+func (ctrl *CarController) GetValuationModels(c *gin.Context) {
+	infos, err := ctrl.service.ValuationModels(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if infos == nil {
+		infos = []models.ValuationModelInfo{}
+	}
+	c.JSON(http.StatusOK, infos)
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/config"
+)
+
+// unreachable returns a config for a port nothing listens on.
+func unreachable(attempts int, backoff time.Duration) *config.Config {
+	return &config.Config{
+		DBHost:            "127.0.0.1",
+		DBPort:            "1",
+		DBUser:            "postgres",
+		DBName:            "car_listing",
+		DBSSLMode:         "disable",
+		DBConnectAttempts: attempts,
+		DBConnectBackoff:  backoff,
+	}
+}
+
+func TestConnectGivesUpAfterAttempts(t *testing.T) {
+	start := time.Now()
+	_, err := Connect(context.Background(), unreachable(3, 10*time.Millisecond))
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Fatalf("err = %v, want a failure after 3 attempts", err)
+	}
+	// Backoff doubles between attempts: 10ms, then 20ms.
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("gave up after %v, want at least 30ms of backoff", elapsed)
+	}
+}
+
+func TestConnectStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := Connect(ctx, unreachable(100, time.Hour))
+	if err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
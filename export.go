@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/export"
+	"github.com/yourusername/car-listing-service/models"
+)
+
+func runExport(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("output", "-", "file to write, or - for stdout")
+	formatName := flags.String("format", string(export.NDJSON), "output format: csv, ndjson or xlsx")
+	deleted := flags.String("deleted", string(models.DeletedExclude), "deleted listings: exclude, include or only")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	filter := models.CarFilter{Deleted: models.DeletedFilter(*deleted)}
+	switch filter.Deleted {
+	case models.DeletedExclude, models.DeletedInclude, models.DeletedOnly:
+	default:
+		return fmt.Errorf("%w: -deleted must be exclude, include or only", errUsage)
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close(context.Background())
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	count, err := a.service.ExportCars(ctx, filter, format, out)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d listings\n", count)
+	return nil
+}
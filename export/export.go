@@ -0,0 +1,220 @@
+// Package export writes car listings as CSV, NDJSON or XLSX one row at a
+// time, so callers can stream from a database cursor without holding the
+// whole result in memory.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+	XLSX   Format = "xlsx"
+)
+
+// Formats lists the supported formats in the order they are documented.
+var Formats = []Format{CSV, NDJSON, XLSX}
+
+// ContentType returns the MIME type of f.
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// ParseFormat validates a format name.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported export format %q", name)
+}
+
+// Writer encodes cars one at a time. Close must be called to flush the
+// output; it does not close the underlying io.Writer.
+type Writer interface {
+	Write(car *models.Car) error
+	Close() error
+}
+
+// NewWriter returns a Writer encoding f to w.
+func NewWriter(f Format, w io.Writer) (Writer, error) {
+	switch f {
+	case CSV:
+		return newCSVWriter(w)
+	case NDJSON:
+		return newNDJSONWriter(w), nil
+	case XLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", f)
+	}
+}
+
+var header = []string{
+	"id", "title", "price", "currency", "year", "mileage", "location", "link", "seller",
+	"version", "created_at", "updated_at", "deleted_at",
+}
+
+func record(car *models.Car) []string {
+	deletedAt := ""
+	if car.DeletedAt != nil {
+		deletedAt = car.DeletedAt.Format(time.RFC3339)
+	}
+	return []string{
+		strconv.Itoa(car.ID), car.Title, car.Price, car.Currency, car.Year,
+		car.Mileage, car.Location, car.Link, car.Seller, strconv.Itoa(car.Version),
+		car.CreatedAt.Format(time.RFC3339), car.UpdatedAt.Format(time.RFC3339), deletedAt,
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (w *csvWriter) Write(car *models.Car) error {
+	fields := record(car)
+	for i, field := range fields {
+		fields[i] = neutralizeFormula(field)
+	}
+	return w.w.Write(fields)
+}
+
+// neutralizeFormula prefixes scraped text that a spreadsheet would run as a
+// formula when the CSV is opened. XLSX cells are typed, so they need no
+// escaping.
+func neutralizeFormula(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+	return &ndjsonWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+func (w *ndjsonWriter) Write(car *models.Car) error {
+	return w.enc.Encode(car)
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.buf.Flush()
+}
+
+// xlsxWriter uses excelize's stream writer, which spills rows to a
+// temporary file instead of keeping the sheet in memory. The workbook is
+// only written to w on Close, since XLSX is a zip archive.
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+const xlsxSheet = "Cars"
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	xw := &xlsxWriter{out: w, file: file, stream: stream, row: 1}
+	cells := make([]interface{}, len(header))
+	for i, name := range header {
+		cells[i] = name
+	}
+	if err := xw.writeRow(cells); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (w *xlsxWriter) Write(car *models.Car) error {
+	fields := record(car)
+	cells := make([]interface{}, len(fields))
+	for i, field := range fields {
+		cells[i] = field
+	}
+	// Keep numeric columns numeric so spreadsheets can sort and sum them.
+	cells[columnIndex("id")] = car.ID
+	cells[columnIndex("version")] = car.Version
+	return w.writeRow(cells)
+}
+
+// columnIndex returns the position of name in header.
+func columnIndex(name string) int {
+	for i, column := range header {
+		if column == name {
+			return i
+		}
+	}
+	panic("export: no column " + name)
+}
+
+func (w *xlsxWriter) writeRow(cells []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	w.row++
+	return w.stream.SetRow(cell, cells)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := w.file.WriteTo(w.out)
+	return err
+}
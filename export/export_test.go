@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/xuri/excelize/v2"
+)
+
+var testCar = models.Car{
+	ID:        42,
+	Title:     "=2018 Toyota Vios",
+	Price:     "₱450,000",
+	Currency:  "PHP",
+	Year:      "2018",
+	Mileage:   "60K km",
+	Location:  "Makati",
+	Link:      "https://www.facebook.com/marketplace/item/42/",
+	Seller:    "100042",
+	Version:   3,
+	CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	UpdatedAt: time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC),
+}
+
+// export writes testCar in format f and returns the output.
+func export(t *testing.T, f Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(f, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	car := testCar
+	if err := w.Write(&car); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// checkRow compares an exported header and row with testCar, by column name.
+func checkRow(t *testing.T, gotHeader, row []string, title string) {
+	t.Helper()
+	if len(gotHeader) != len(header) {
+		t.Fatalf("header = %v, want %v", gotHeader, header)
+	}
+	for i := range header {
+		if gotHeader[i] != header[i] {
+			t.Fatalf("header = %v, want %v", gotHeader, header)
+		}
+	}
+
+	want := map[string]string{
+		"id":         "42",
+		"title":      title,
+		"price":      "₱450,000",
+		"currency":   "PHP",
+		"year":       "2018",
+		"mileage":    "60K km",
+		"location":   "Makati",
+		"link":       "https://www.facebook.com/marketplace/item/42/",
+		"seller":     "100042",
+		"version":    "3",
+		"created_at": "2026-01-02T03:04:05Z",
+		"updated_at": "2026-02-03T04:05:06Z",
+		"deleted_at": "",
+	}
+	for i, name := range header {
+		got := ""
+		if i < len(row) {
+			got = row[i]
+		}
+		if got != want[name] {
+			t.Errorf("%s = %q, want %q", name, got, want[name])
+		}
+	}
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(export(t, CSV))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("read %d records, want header and one row", len(records))
+	}
+	// The title starts with "=", so it is escaped against formula injection.
+	checkRow(t, records[0], records[1], "'=2018 Toyota Vios")
+}
+
+func TestXLSX(t *testing.T) {
+	file, err := excelize.OpenReader(bytes.NewReader(export(t, XLSX)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows(xlsxSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("read %d rows, want header and one row", len(rows))
+	}
+	checkRow(t, rows[0], rows[1], "=2018 Toyota Vios")
+
+	for _, name := range []string{"id", "version"} {
+		cell, err := excelize.CoordinatesToCellName(columnIndex(name)+1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ, err := file.GetCellType(xlsxSheet, cell); err != nil || typ == excelize.CellTypeSharedString || typ == excelize.CellTypeInlineString {
+			t.Errorf("%s cell %s has type %v (err %v), want a number", name, cell, typ, err)
+		}
+	}
+}
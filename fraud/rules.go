@@ -0,0 +1,111 @@
+// Package fraud scores listings for signs of scams with weighted rules. Each
+// rule that fires becomes a flag with a reason a moderator can read.
+package fraud
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+// ReviewThreshold is the score from which a listing is queued for
+// moderators by default.
+const ReviewThreshold = 2
+
+// SellerWindow is how far back a seller's listings count as recent posts.
+const SellerWindow = 7 * 24 * time.Hour
+
+const (
+	// farBelowScore is the deal score from which a price is suspiciously
+	// low: half the comparables' median or less.
+	farBelowScore = 0.5
+	// reusedTitleCount is how many other live listings may share a title
+	// before it looks copied.
+	reusedTitleCount = 3
+	// sellerRecentPosts is how many live listings a seller may post within
+	// SellerWindow before the volume looks like a scam operation.
+	sellerRecentPosts = 5
+)
+
+// suspiciousPhrases are wordings typical of loan-assumption and deposit
+// scams, matched in lowercased titles. "Pasalo" is Tagalog for handing over
+// a car on an unpaid loan.
+var suspiciousPhrases = []string{
+	"downpayment only", "down payment only", "dp only", "low dp", "all in dp",
+	"assume balance", "assume the balance", "pasalo", "reservation fee",
+}
+
+// Rule checks one signal. Check returns the reason when it fires.
+type Rule struct {
+	Name   string
+	Weight float64
+	Check  func(candidate models.FraudCandidate) (reason string, fired bool)
+}
+
+// Rules are the checks run after every scrape job.
+var Rules = []Rule{
+	{Name: "price_far_below_comparables", Weight: 3, Check: priceFarBelowComparables},
+	{Name: "reused_title", Weight: 2, Check: reusedTitle},
+	{Name: "suspicious_keywords", Weight: 2, Check: suspiciousKeywords},
+	{Name: "seller_many_recent_posts", Weight: 2, Check: sellerManyRecentPosts},
+	{Name: "missing_mileage", Weight: 1, Check: missingMileage},
+}
+
+// Evaluate runs rules on candidate and returns a flag for each that fired.
+func Evaluate(rules []Rule, candidate models.FraudCandidate) []models.FraudFlag {
+	var flags []models.FraudFlag
+	for _, rule := range rules {
+		if reason, fired := rule.Check(candidate); fired {
+			flags = append(flags, models.FraudFlag{
+				CarID:  candidate.Car.ID,
+				Rule:   rule.Name,
+				Weight: rule.Weight,
+				Reason: reason,
+			})
+		}
+	}
+	return flags
+}
+
+func priceFarBelowComparables(candidate models.FraudCandidate) (string, bool) {
+	deal := candidate.Car.Deal
+	if deal == nil || deal.Score < farBelowScore {
+		return "", false
+	}
+	return fmt.Sprintf("Asks %.0f%% below the %.0f median of %d comparable listings",
+		deal.Score*100, deal.FairPrice, deal.Comparables), true
+}
+
+func reusedTitle(candidate models.FraudCandidate) (string, bool) {
+	if candidate.TitleReuse < reusedTitleCount {
+		return "", false
+	}
+	return fmt.Sprintf("Title is shared by %d other listings", candidate.TitleReuse), true
+}
+
+func suspiciousKeywords(candidate models.FraudCandidate) (string, bool) {
+	title := strings.Join(strings.Fields(strings.ToLower(candidate.Car.Title)), " ")
+	for _, phrase := range suspiciousPhrases {
+		if strings.Contains(title, phrase) {
+			return fmt.Sprintf("Title mentions %q", phrase), true
+		}
+	}
+	return "", false
+}
+
+func sellerManyRecentPosts(candidate models.FraudCandidate) (string, bool) {
+	if candidate.Car.Seller == "" || candidate.SellerRecentPosts < sellerRecentPosts {
+		return "", false
+	}
+	return fmt.Sprintf("Seller posted %d listings in the last %d days",
+		candidate.SellerRecentPosts, int(SellerWindow.Hours()/24)), true
+}
+
+func missingMileage(candidate models.FraudCandidate) (string, bool) {
+	if strings.TrimSpace(candidate.Car.Mileage) != "" {
+		return "", false
+	}
+	return "Listing gives no mileage", true
+}
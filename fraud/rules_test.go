@@ -0,0 +1,141 @@
+package fraud
+
+import (
+	"testing"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+func TestPriceFarBelowComparables(t *testing.T) {
+	tests := []struct {
+		name string
+		deal *models.DealScore
+		want bool
+	}{
+		{name: "not scored", deal: nil},
+		{name: "fair price", deal: &models.DealScore{Score: 0.05, FairPrice: 500000, Comparables: 8}},
+		{name: "just above half", deal: &models.DealScore{Score: 0.49, FairPrice: 500000, Comparables: 8}},
+		{name: "half the median", deal: &models.DealScore{Score: farBelowScore, FairPrice: 500000, Comparables: 8}, want: true},
+		{name: "far below", deal: &models.DealScore{Score: 0.7, FairPrice: 500000, Comparables: 8}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, fired := priceFarBelowComparables(models.FraudCandidate{Car: models.Car{Deal: tt.deal}})
+			if fired != tt.want {
+				t.Errorf("fired = %v, want %v", fired, tt.want)
+			}
+			if fired == (reason == "") {
+				t.Errorf("reason = %q with fired = %v", reason, fired)
+			}
+		})
+	}
+}
+
+func TestReusedTitle(t *testing.T) {
+	tests := []struct {
+		reuse int
+		want  bool
+	}{
+		{0, false},
+		{reusedTitleCount - 1, false},
+		{reusedTitleCount, true},
+		{10, true},
+	}
+	for _, tt := range tests {
+		_, fired := reusedTitle(models.FraudCandidate{TitleReuse: tt.reuse})
+		if fired != tt.want {
+			t.Errorf("title shared by %d others: fired = %v, want %v", tt.reuse, fired, tt.want)
+		}
+	}
+}
+
+func TestSuspiciousKeywords(t *testing.T) {
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{"2018 Toyota Vios 1.3 E", false},
+		{"2018 Toyota Vios DP only", true},
+		{"2018 TOYOTA VIOS PASALO", true},
+		{"Honda City  Low\tDP  all in", true},
+		{"Montero Sport assume   the balance", true},
+		{"Navara, dp. only", false},
+		{"Ranger with reservation fee", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			_, fired := suspiciousKeywords(models.FraudCandidate{Car: models.Car{Title: tt.title}})
+			if fired != tt.want {
+				t.Errorf("fired = %v, want %v", fired, tt.want)
+			}
+		})
+	}
+}
+
+func TestSellerManyRecentPosts(t *testing.T) {
+	tests := []struct {
+		name   string
+		seller string
+		posts  int
+		want   bool
+	}{
+		{name: "unknown seller", seller: "", posts: 20},
+		{name: "few posts", seller: "100001", posts: sellerRecentPosts - 1},
+		{name: "at the threshold", seller: "100001", posts: sellerRecentPosts, want: true},
+		{name: "many posts", seller: "100001", posts: 30, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, fired := sellerManyRecentPosts(models.FraudCandidate{
+				Car:               models.Car{Seller: tt.seller},
+				SellerRecentPosts: tt.posts,
+			})
+			if fired != tt.want {
+				t.Errorf("fired = %v, want %v", fired, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingMileage(t *testing.T) {
+	for mileage, want := range map[string]bool{"": true, "  ": true, "50K km": false} {
+		_, fired := missingMileage(models.FraudCandidate{Car: models.Car{Mileage: mileage}})
+		if fired != want {
+			t.Errorf("mileage %q: fired = %v, want %v", mileage, fired, want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	candidate := models.FraudCandidate{
+		Car: models.Car{
+			ID:    42,
+			Title: "2019 Toyota Fortuner pasalo",
+			Deal:  &models.DealScore{Score: 0.6, FairPrice: 1500000, Comparables: 5},
+		},
+		TitleReuse: 1,
+	}
+
+	flags := Evaluate(Rules, candidate)
+	want := map[string]float64{
+		"price_far_below_comparables": 3,
+		"suspicious_keywords":         2,
+		"missing_mileage":             1,
+	}
+	if len(flags) != len(want) {
+		t.Fatalf("flags = %+v, want rules %v", flags, want)
+	}
+	for _, flag := range flags {
+		weight, ok := want[flag.Rule]
+		if !ok || flag.Weight != weight {
+			t.Errorf("flag %s with weight %v, want one of %v", flag.Rule, flag.Weight, want)
+		}
+		if flag.CarID != candidate.Car.ID || flag.Reason == "" {
+			t.Errorf("flag %+v lacks the car ID or a reason", flag)
+		}
+	}
+
+	if flags := Evaluate(Rules, models.FraudCandidate{Car: models.Car{Title: "2015 Honda City", Mileage: "80K km"}}); len(flags) != 0 {
+		t.Errorf("clean listing flagged: %+v", flags)
+	}
+}
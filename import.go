@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/importer"
+	"github.com/yourusername/car-listing-service/models"
+)
+
+func runImport(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	input := flags.String("input", "-", "CSV or NDJSON file to read, or - for stdin")
+	formatName := flags.String("format", "", "input format: csv or ndjson (default from the -input extension, else ndjson)")
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without storing anything")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *formatName == "" {
+		*formatName = string(importer.NDJSON)
+		if ext := strings.TrimPrefix(filepath.Ext(*input), "."); ext != "" {
+			*formatName = ext
+		}
+	}
+	format, err := importer.ParseFormat(*formatName)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close(context.Background())
+
+	var in io.Reader = os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	report, err := a.service.ImportListings(ctx, format, in, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range report.Rows {
+		if row.Status == models.ImportAccepted {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "line %d: %s: %s", row.Line, row.Status, row.Reason)
+		if row.Message != "" {
+			fmt.Fprintf(os.Stderr, ": %s", row.Message)
+		}
+		for _, field := range row.Errors {
+			fmt.Fprintf(os.Stderr, "; %s %s", field.Field, field.Message)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
+	verb := "imported"
+	if *dryRun {
+		verb = "would import"
+	}
+	fmt.Fprintf(os.Stderr, "read %d listings: %s %d, %d duplicates, %d rejected\n", report.Total, verb, report.Accepted, report.Duplicates, report.Rejected)
+	if report.Rejected > 0 {
+		return fmt.Errorf("%d listings rejected", report.Rejected)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Package importer reads car listings from CSV and NDJSON files, mapping
+// columns or keys onto models.CarRequest by name and validating each row
+// with the same rules as the API.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/validation"
+)
+
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+)
+
+// ParseFormat validates a format name, accepting "jsonl" for NDJSON.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "csv":
+		return CSV, nil
+	case "ndjson", "jsonl":
+		return NDJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported import format %q", name)
+	}
+}
+
+// Row is one data line. Err is set when the line could not be decoded or
+// failed validation; it is an *apperror.Error listing the bad fields.
+type Row struct {
+	Line int
+	Car  models.CarRequest
+	Err  error
+}
+
+// aliases maps normalized column names onto CarRequest fields.
+var aliases = map[string]string{
+	"title":        "title",
+	"name":         "title",
+	"listing":      "title",
+	"price":        "price",
+	"asking_price": "price",
+	"amount":       "price",
+	"currency":     "currency",
+	"year":         "year",
+	"model_year":   "year",
+	"mileage":      "mileage",
+	"odometer":     "mileage",
+	"km":           "mileage",
+	"location":     "location",
+	"city":         "location",
+	"link":         "link",
+	"url":          "link",
+	"listing_url":  "link",
+	"seller":       "seller",
+	"seller_id":    "seller",
+	"profile":      "seller",
+}
+
+// normalizeKey lowercases a column name and turns spaces and dashes into
+// underscores, so "Asking Price" matches asking_price.
+func normalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(key, "\uFEFF")))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
+
+func setField(req *models.CarRequest, field, value string) {
+	value = strings.TrimSpace(value)
+	switch field {
+	case "title":
+		req.Title = value
+	case "price":
+		req.Price = value
+	case "currency":
+		req.Currency = strings.ToUpper(value)
+	case "year":
+		req.Year = value
+	case "mileage":
+		req.Mileage = value
+	case "location":
+		req.Location = value
+	case "link":
+		req.Link = value
+	case "seller":
+		req.Seller = value
+	}
+}
+
+// Read decodes r and calls fn with every non-blank data line in order. It
+// returns early with fn's error. A file that cannot be read as a whole, such
+// as a CSV without a link column, fails with an "invalid_file" validation
+// error.
+func Read(format Format, r io.Reader, fn func(Row) error) error {
+	switch format {
+	case CSV:
+		return readCSV(r, fn)
+	case NDJSON:
+		return readNDJSON(r, fn)
+	default:
+		return fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+func readCSV(r io.Reader, fn func(Row) error) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return invalidFile("Could not read the CSV header", err)
+	}
+
+	columns := make([]string, len(header))
+	mapped := make(map[string]bool)
+	for i, name := range header {
+		columns[i] = aliases[normalizeKey(name)]
+		mapped[columns[i]] = true
+	}
+	for _, required := range []string{"title", "price", "link"} {
+		if !mapped[required] {
+			return apperror.Validation("invalid_file", "CSV header has no "+required+" column")
+		}
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if err := fn(Row{Line: parseErr.StartLine, Err: apperror.Validation("invalid_row", parseErr.Err.Error())}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if blank(record) {
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+
+		var req models.CarRequest
+		for i, value := range record {
+			if i < len(columns) {
+				setField(&req, columns[i], value)
+			}
+		}
+		if err := fn(Row{Line: line, Car: req, Err: validation.Struct(&req)}); err != nil {
+			return err
+		}
+	}
+}
+
+func blank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func readNDJSON(r io.Reader, fn func(Row) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var object map[string]interface{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			if err := fn(Row{Line: line, Err: apperror.Validation("invalid_row", "Line is not a JSON object")}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var req models.CarRequest
+		for key, value := range object {
+			if field, ok := aliases[normalizeKey(key)]; ok {
+				setField(&req, field, stringValue(value))
+			}
+		}
+		if err := fn(Row{Line: line, Car: req, Err: validation.Struct(&req)}); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return invalidFile("Could not read the NDJSON file", err)
+	}
+	return nil
+}
+
+// invalidFile reports a file-level decode failure. Reader errors that are not
+// about the file's contents, such as a body size limit, pass through.
+func invalidFile(message string, err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) || errors.Is(err, bufio.ErrTooLong) {
+		return apperror.Validation("invalid_file", message+": "+err.Error())
+	}
+	return err
+}
+
+// stringValue renders JSON scalars the way a spreadsheet cell would read,
+// so {"year": 2015} imports as "2015".
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
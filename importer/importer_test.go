@@ -0,0 +1,93 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/importer"
+)
+
+// These tests never call validation.Register, as the import command does
+// not: rows must still validate with the custom car rules.
+
+func readAll(t *testing.T, format importer.Format, input string) []importer.Row {
+	t.Helper()
+	var rows []importer.Row
+	err := importer.Read(format, strings.NewReader(input), func(row importer.Row) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return rows
+}
+
+func fieldCodes(err error) map[string]string {
+	codes := make(map[string]string)
+	for _, field := range apperror.From(err).Fields {
+		codes[field.Field] = field.Code
+	}
+	return codes
+}
+
+func TestReadCSVValidatesRows(t *testing.T) {
+	rows := readAll(t, importer.CSV, "Name,Asking Price,Model Year,URL\n"+
+		"2018 Toyota Vios,\"₱450,000\",2018,https://www.facebook.com/marketplace/item/123/\n"+
+		",,,\n"+
+		"2019 Honda City,cheap,1800,https://example.com/item/1\n")
+
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	if rows[0].Err != nil {
+		t.Errorf("line %d: unexpected error %v", rows[0].Line, rows[0].Err)
+	}
+	if rows[0].Line != 2 || rows[0].Car.Title != "2018 Toyota Vios" || rows[0].Car.Price != "₱450,000" {
+		t.Errorf("first row = %+v", rows[0])
+	}
+
+	if rows[1].Line != 4 {
+		t.Errorf("second row line = %d, want 4", rows[1].Line)
+	}
+	want := map[string]string{"price": "car_price", "year": "car_year", "link": "marketplace_item"}
+	got := fieldCodes(rows[1].Err)
+	for field, code := range want {
+		if got[field] != code {
+			t.Errorf("field %s: code %q, want %q (all: %v)", field, got[field], code, got)
+		}
+	}
+}
+
+func TestReadNDJSONValidatesRows(t *testing.T) {
+	rows := readAll(t, importer.NDJSON,
+		`{"title": "2015 Mitsubishi Mirage", "price": "PHP 280000", "year": 2015, "link": "https://m.facebook.com/marketplace/item/9/"}`+"\n"+
+			"\n"+
+			"not json\n"+
+			`{"title": "No price", "link": "https://www.facebook.com/marketplace/item/10/"}`+"\n")
+
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if rows[0].Err != nil || rows[0].Car.Year != "2015" {
+		t.Errorf("line %d = %+v", rows[0].Line, rows[0])
+	}
+	if code := apperror.From(rows[1].Err).Code; rows[1].Line != 3 || code != "invalid_row" {
+		t.Errorf("line %d: code %q, want invalid_row on line 3", rows[1].Line, code)
+	}
+	if code := fieldCodes(rows[2].Err)["price"]; code != "required" {
+		t.Errorf("line %d: price code %q, want required", rows[2].Line, code)
+	}
+}
+
+func TestReadCSVWithoutLinkColumn(t *testing.T) {
+	err := importer.Read(importer.CSV, strings.NewReader("title,price\nCar,100\n"), func(importer.Row) error {
+		t.Fatal("no row expected")
+		return nil
+	})
+	if appErr := apperror.From(err); appErr.Kind != apperror.KindValidation || appErr.Code != "invalid_file" {
+		t.Errorf("err = %v, want invalid_file validation error", err)
+	}
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestContextHandlerAddsIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(contextHandler{slog.NewJSONHandler(&buf, nil)}).With("service", "test")
+
+	ctx := WithJobID(WithRequestID(context.Background(), "req-1"), "job-1")
+	logger.InfoContext(ctx, "with IDs")
+	logger.InfoContext(context.Background(), "without IDs")
+
+	decoder := json.NewDecoder(&buf)
+	for _, want := range []map[string]string{
+		{"msg": "with IDs", "request_id": "req-1", "job_id": "job-1", "service": "test"},
+		{"msg": "without IDs", "request_id": "", "job_id": "", "service": "test"},
+	} {
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		for key, value := range want {
+			got, _ := record[key].(string)
+			if got != value {
+				t.Errorf("%s: %s = %q, want %q", want["msg"], key, got, value)
+			}
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for level, want := range map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"WARN":  slog.LevelWarn,
+		"error": slog.LevelError,
+		"":      slog.LevelInfo,
+		"loud":  slog.LevelInfo,
+	} {
+		if got := parseLevel(level); got != want {
+			t.Errorf("parseLevel(%q) = %v, want %v", level, got, want)
+		}
+	}
+}
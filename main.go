@@ -2,83 +2,132 @@ package main
 
 import (
 	"context"
-	"log"
-	"net/http"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
-	"time"
 
 	"github.com/yourusername/car-listing-service/config"
-	"github.com/yourusername/car-listing-service/controllers"
 	"github.com/yourusername/car-listing-service/database"
-	"github.com/yourusername/car-listing-service/middleware"
+	"github.com/yourusername/car-listing-service/logging"
 	"github.com/yourusername/car-listing-service/repository"
-	"github.com/yourusername/car-listing-service/routes"
 	"github.com/yourusername/car-listing-service/services"
-	"github.com/gin-gonic/gin"
+	"github.com/yourusername/car-listing-service/tracing"
 )
 
-func setupRouter(cfg *config.Config) *gin.Engine {
-	if cfg.Environment == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	}
-
-	router := gin.New()
-	router.Use(gin.Recovery())
-	router.Use(middleware.Logger())
-	router.Use(middleware.CORS())
-
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "healthy",
-			"service": "car-listing-service",
-		})
-	})
-
-	carRepo := repository.NewCarRepository(database.DB)
-	carService := services.NewCarService(carRepo)
-	carController := controllers.NewCarController(carService)
-
-	routes.SetupRoutes(router, carController)
-
-	return router
+const usage = `usage: car-listing-service <command> [flags]
+
+commands:
+  serve      run the HTTP API (default)
+  scrape     run one scrape job and store the results
+  migrate    apply, revert or list database migrations
+  export     write stored listings as CSV, NDJSON or XLSX
+  import     load listings from CSV or NDJSON, skipping known links
+  backfill   derive missing currency and year on stored listings
+  purge      permanently remove listings deleted longer ago than the retention
+  retrain    train a new valuation model from stored listings
+
+Run "car-listing-service <command> -h" for command flags.`
+
+// errUsage marks command-line mistakes, which exit with status 2.
+var errUsage = errors.New("usage error")
+
+type command func(ctx context.Context, cfg *config.Config, args []string) error
+
+var commands = map[string]command{
+	"serve":    runServe,
+	"scrape":   runScrape,
+	"migrate":  runMigrate,
+	"export":   runExport,
+	"import":   runImport,
+	"backfill": runBackfill,
+	"purge":    runPurge,
+	"retrain":  runRetrain,
 }
 
 func main() {
 	cfg := config.LoadConfig()
+	logging.Setup(cfg.Log)
 
-	database.InitDB()
-
-	router := setupRouter(cfg)
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	srv := &http.Server{
-		Addr:           ":" + cfg.ServerPort,
-		Handler:        router,
-		ReadTimeout:    300 * time.Second,
-		WriteTimeout:   300 * time.Second,
-		MaxHeaderBytes: 1 << 20,
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
 	}
 
-	go func() {
-		log.Printf("Starting server on port %s in %s mode", cfg.ServerPort, cfg.Environment)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err := cmd(ctx, cfg, args)
+	stop()
+
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
 		}
-	}()
+		os.Exit(1)
+	}
+}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+// parseFlags parses args into flags, marking bad flags as usage errors.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return nil
+}
 
-	log.Println("Shutting down server...")
+// app holds the wiring shared by every command.
+type app struct {
+	db              *sql.DB
+	repo            repository.CarRepository
+	service         services.CarService
+	shutdownTracing func(context.Context) error
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func newApp(ctx context.Context, cfg *config.Config) (*app, error) {
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("initialise tracing: %w", err)
+	}
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+	db, err := database.Connect(ctx, cfg)
+	if err != nil {
+		shutdownTracing(ctx)
+		return nil, fmt.Errorf("connect to database: %w", err)
 	}
 
-	log.Println("Server exited")
+	repo := repository.NewCarRepository(db, repository.Timeouts{
+		Read:  cfg.DBReadTimeout,
+		Write: cfg.DBWriteTimeout,
+		Batch: cfg.DBBatchTimeout,
+	})
+	return &app{
+		db:              db,
+		repo:            repo,
+		service:         services.NewCarService(repo, cfg.Scraper),
+		shutdownTracing: shutdownTracing,
+	}, nil
+}
+
+func (a *app) Close(ctx context.Context) {
+	if err := a.shutdownTracing(ctx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+	a.db.Close()
 }
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/config"
+)
+
+// Each case fails before the command connects to the database, so none
+// needs one.
+func TestCommandUsageErrors(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+		want    error
+	}{
+		{command: "serve", args: []string{"-nope"}, want: errUsage},
+		{command: "scrape", args: []string{"-nope"}, want: errUsage},
+		{command: "migrate", want: errUsage},
+		{command: "export", args: []string{"-format", "pdf"}, want: errUsage},
+		{command: "export", args: []string{"-deleted", "all"}, want: errUsage},
+		{command: "import", args: []string{"-input", "cars.xml"}, want: errUsage},
+		{command: "backfill", args: []string{"-nope"}, want: errUsage},
+		{command: "purge", args: []string{"-nope"}, want: errUsage},
+		{command: "retrain", args: []string{"-nope"}, want: errUsage},
+		{command: "export", args: []string{"-h"}, want: flag.ErrHelp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command+" "+strings.Join(tt.args, " "), func(t *testing.T) {
+			err := commands[tt.command](context.Background(), &config.Config{}, tt.args)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageListsCommands(t *testing.T) {
+	for name := range commands {
+		if !strings.Contains(usage, "\n  "+name+" ") {
+			t.Errorf("usage does not list %q", name)
+		}
+	}
+}
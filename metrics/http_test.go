@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+func TestRegisterDBStats(t *testing.T) {
+	// Opening does not connect, so the pool reports zero connections.
+	db, err := sql.Open("postgres", "postgres://localhost:1/none?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	RegisterDBStats(db)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	for _, series := range []string{
+		`go_sql_open_connections{db_name="car_listing"} 0`,
+		`go_sql_in_use_connections{db_name="car_listing"} 0`,
+		`go_sql_wait_count_total{db_name="car_listing"} 0`,
+	} {
+		if !strings.Contains(recorder.Body.String(), series) {
+			t.Errorf("/metrics does not expose %s", series)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "car_listing"
+
+var (
+	ScraperScrollCycles = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "scroll_cycles_total",
+		Help:      "Scroll cycles performed by the Marketplace scraper.",
+	})
+
+	ScraperItemsExtracted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "items_extracted_total",
+		Help:      "Listings extracted from the page, including ones already seen this session.",
+	})
+
+	ScraperNewItems = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "new_items_total",
+		Help:      "Listings seen for the first time in a scrape session.",
+	})
+
+	ScraperDuplicates = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "duplicates_total",
+		Help:      "Extracted listings already seen earlier in the same session.",
+	})
+
+	ScraperInserted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "inserted_total",
+		Help:      "Scraped listings inserted into the database.",
+	})
+
+	ScraperStoreFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "store_failures_total",
+		Help:      "Scraped listings the database rejected, by reason.",
+	}, []string{"reason"})
+
+	ScraperDelay = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "delay_seconds",
+		Help:      "Current adaptive delay between scroll cycles.",
+	})
+
+	ScraperJobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "job_duration_seconds",
+		Help:      "Wall-clock duration of scrape jobs.",
+		Buckets:   []float64{30, 60, 120, 300, 600, 900, 1800, 2700, 3600, 5400, 7200},
+	}, []string{"status"})
+
+	ScraperStops = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "stops_total",
+		Help:      "Scrape sessions stopped, by reason.",
+	}, []string{"reason"})
+
+	ScraperLoginAttempts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "login_attempts_total",
+		Help:      "Facebook logins attempted because saved cookies were missing or unusable.",
+	})
+
+	ScraperLoginFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scraper",
+		Name:      "login_failures_total",
+		Help:      "Facebook logins that failed.",
+	})
+)
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body, extended with a stable error
+// code, the request ID and field-level validation errors.
+type Problem struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty"`
+	Code      string                 `json:"code"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Errors    []apperror.FieldError  `json:"errors,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// ErrorHandler renders the last error a handler attached with c.Error as a
+// problem+json response. Internal errors are logged and answered generically
+// so driver messages never reach clients.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		WriteProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// Recovery turns panics into internal-error problems.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		WriteProblem(c, apperror.Internal(fmt.Errorf("panic: %v", recovered)))
+		c.Abort()
+	})
+}
+
+// NoRoute answers unknown paths with a not-found problem.
+func NoRoute(c *gin.Context) {
+	c.Error(apperror.NotFound("route_not_found", "No route matches "+c.Request.Method+" "+c.Request.URL.Path))
+}
+
+func WriteProblem(c *gin.Context, err error) {
+	appErr := apperror.From(err)
+	status := StatusFor(appErr.Kind)
+
+	if appErr.Kind == apperror.KindInternal || appErr.Kind == apperror.KindUnavailable {
+		slog.ErrorContext(c.Request.Context(), "request failed", "code", appErr.Code, "error", err)
+	}
+
+	problem := Problem{
+		Type:      "/problems/" + appErr.Code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    appErr.Message,
+		Instance:  c.Request.URL.Path,
+		Code:      appErr.Code,
+		RequestID: c.GetString(RequestIDKey),
+		Errors:    appErr.Fields,
+		Details:   appErr.Details,
+	}
+
+	c.Render(status, problemRender{problem})
+}
+
+// problemRender writes a Problem with the problem+json content type, which
+// gin's JSON renderer would overwrite with application/json.
+type problemRender struct {
+	problem Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", problemContentType)
+}
+
+// StatusFor maps an error kind to its HTTP status.
+func StatusFor(kind apperror.Kind) int {
+	switch kind {
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindConflict:
+		return http.StatusConflict
+	case apperror.KindValidation:
+		return http.StatusBadRequest
+	case apperror.KindUnavailable:
+		return http.StatusServiceUnavailable
+	case apperror.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorHandlerWritesProblems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), Recovery(), ErrorHandler())
+	router.NoRoute(NoRoute)
+	router.GET("/validation", func(c *gin.Context) {
+		c.Error(apperror.Validation("invalid_car", "Invalid car", apperror.FieldError{Field: "year", Code: "range", Message: "too old"}))
+	})
+	router.GET("/conflict", func(c *gin.Context) {
+		c.Error(fmt.Errorf("create: %w", apperror.Conflict("duplicate_link", "A car with this link already exists", errors.New("pq: duplicate key"))))
+	})
+	router.GET("/internal", func(c *gin.Context) {
+		c.Error(errors.New("pq: password authentication failed"))
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+		field  string
+	}{
+		{path: "/validation", status: http.StatusBadRequest, code: "invalid_car", field: "year"},
+		{path: "/conflict", status: http.StatusConflict, code: "duplicate_link"},
+		{path: "/internal", status: http.StatusInternalServerError, code: "internal_error"},
+		{path: "/panic", status: http.StatusInternalServerError, code: "internal_error"},
+		{path: "/missing", status: http.StatusNotFound, code: "route_not_found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set(RequestIDHeader, "req-1")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.status {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.status)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != problemContentType {
+				t.Errorf("content type = %q, want %q", got, problemContentType)
+			}
+			var problem Problem
+			if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != tt.code || problem.Status != tt.status || problem.Type != "/problems/"+tt.code ||
+				problem.Instance != tt.path || problem.RequestID != "req-1" {
+				t.Errorf("problem = %+v", problem)
+			}
+			if tt.field != "" && (len(problem.Errors) != 1 || problem.Errors[0].Field != tt.field) {
+				t.Errorf("field errors = %+v, want one for %s", problem.Errors, tt.field)
+			}
+			// Wrapped causes are logged, never sent.
+			if body := recorder.Body.String(); strings.Contains(body, "pq:") || strings.Contains(body, "boom") {
+				t.Errorf("problem leaks the cause: %s", body)
+			}
+		})
+	}
+}
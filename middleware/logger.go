@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,20 +17,32 @@ func Logger() gin.HandlerFunc {
 
 		latency := time.Since(start)
 		statusCode := c.Writer.Status()
-		method := c.Request.Method
-		clientIP := c.ClientIP()
 
 		if raw != "" {
 			path = path + "?" + raw
 		}
 
-		log.Printf("[%s] %s %s %d %v %s",
-			method,
-			path,
-			clientIP,
-			statusCode,
-			latency,
-			c.Errors.String(),
-		)
+		level := slog.LevelInfo
+		switch {
+		case statusCode >= 500:
+			level = slog.LevelError
+		case statusCode >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.String("route", c.FullPath()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("status", statusCode),
+			slog.Duration("latency", latency),
+			slog.Int("size", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
 	}
 }
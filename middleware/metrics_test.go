@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/yourusername/car-listing-service/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsGroupsByRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/api/v1/cars/:id", func(c *gin.Context) {
+		if c.Param("id") == "0" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	requests := func(route, status string) float64 {
+		return testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues("GET", route, status))
+	}
+	ok, notFound, unmatched := requests("/api/v1/cars/:id", "2xx"), requests("/api/v1/cars/:id", "4xx"), requests("unmatched", "4xx")
+
+	for _, path := range []string{"/api/v1/cars/1", "/api/v1/cars/2", "/api/v1/cars/0", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	// Both IDs share one series; paths without a route share "unmatched".
+	if got := requests("/api/v1/cars/:id", "2xx") - ok; got != 2 {
+		t.Errorf("2xx requests to /api/v1/cars/:id = %v, want 2", got)
+	}
+	if got := requests("/api/v1/cars/:id", "4xx") - notFound; got != 1 {
+		t.Errorf("4xx requests to /api/v1/cars/:id = %v, want 1", got)
+	}
+	if got := requests("unmatched", "4xx") - unmatched; got != 1 {
+		t.Errorf("unmatched 4xx requests = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(metrics.HTTPRequestDuration, "car_listing_http_request_duration_seconds"); got < 3 {
+		t.Errorf("latency series = %d, want one per route and status", got)
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/logging"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	var seen string
+	router.GET("/", func(c *gin.Context) {
+		seen = logging.RequestID(c.Request.Context())
+		if c.GetString(RequestIDKey) != seen {
+			t.Errorf("gin context ID %q differs from request context ID %q", c.GetString(RequestIDKey), seen)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "incoming", incoming: "abc-123", keep: true},
+		{name: "missing"},
+		{name: "too long", incoming: strings.Repeat("x", 129)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			echoed := recorder.Header().Get(RequestIDHeader)
+			if echoed != seen {
+				t.Errorf("echoed ID %q, handler saw %q", echoed, seen)
+			}
+			if tt.keep && echoed != tt.incoming {
+				t.Errorf("ID = %q, want the incoming %q", echoed, tt.incoming)
+			}
+			if !tt.keep && len(echoed) != 32 {
+				t.Errorf("ID = %q, want a generated 32-character one", echoed)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS blocked_links;
+DROP INDEX IF EXISTS cars_deleted_at_idx;
+ALTER TABLE cars DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,10 @@
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS cars_deleted_at_idx ON cars (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Links of purged listings, so scrapes and imports never bring them back.
+CREATE TABLE IF NOT EXISTS blocked_links (
+    link TEXT PRIMARY KEY,
+    reason TEXT NOT NULL,
+    blocked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS cars_make_model_year_idx;
+
+ALTER TABLE cars
+    DROP COLUMN IF EXISTS model,
+    DROP COLUMN IF EXISTS make,
+    DROP COLUMN IF EXISTS mileage_km,
+    DROP COLUMN IF EXISTS year_num,
+    DROP COLUMN IF EXISTS price_amount;
@@ -0,0 +1,30 @@
+-- Numeric and categorical attributes parsed from the scraped text, for
+-- aggregate queries. They are derived by the database, so every writer gets
+-- them and the patterns only accept digits, keeping the casts from failing.
+
+-- The first number in the price; "₱450,000₱500,000" (a price drop) is 450000.
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS price_amount NUMERIC
+    GENERATED ALWAYS AS (replace(substring(price FROM '\d[\d,]*(?:\.\d+)?'), ',', '')::numeric) STORED;
+
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS year_num INTEGER
+    GENERATED ALWAYS AS (CASE WHEN year ~ '^\s*(19|20)\d{2}\s*$' THEN btrim(year)::integer END) STORED;
+
+-- Kilometres: "50K km" is 50000, miles are converted.
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS mileage_km NUMERIC
+    GENERATED ALWAYS AS (round(
+        replace(substring(lower(mileage) FROM '\d[\d,]*(?:\.\d+)?'), ',', '')::numeric
+        * CASE WHEN lower(mileage) ~ '\d\s*k(?!m)' THEN 1000 ELSE 1 END
+        * CASE WHEN lower(mileage) ~ '\mmi(les?)?\M' THEN 1.609344 ELSE 1 END
+    )) STORED;
+
+-- Make and model are the first two words of the title after a leading
+-- year, lowercased: "2015 Toyota Vios 1.3 E" is toyota / vios.
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS make TEXT
+    GENERATED ALWAYS AS (lower(NULLIF(split_part(
+        regexp_replace(btrim(regexp_replace(title, '\s+', ' ', 'g')), '^(19|20)\d{2} ', ''), ' ', 1), ''))) STORED;
+
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS model TEXT
+    GENERATED ALWAYS AS (lower(NULLIF(split_part(
+        regexp_replace(btrim(regexp_replace(title, '\s+', ' ', 'g')), '^(19|20)\d{2} ', ''), ' ', 2), ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS cars_make_model_year_idx ON cars (make, model, year_num) WHERE deleted_at IS NULL;
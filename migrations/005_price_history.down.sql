@@ -0,0 +1,5 @@
+DROP MATERIALIZED VIEW IF EXISTS price_trend_points;
+DROP TRIGGER IF EXISTS cars_price_repriced ON cars;
+DROP TRIGGER IF EXISTS cars_price_listed ON cars;
+DROP FUNCTION IF EXISTS record_car_price();
+DROP TABLE IF EXISTS car_price_history;
@@ -0,0 +1,65 @@
+-- Every asking price a listing has had: the one it was first stored with and
+-- each change after that.
+CREATE TABLE IF NOT EXISTS car_price_history (
+    id BIGSERIAL PRIMARY KEY,
+    car_id INTEGER NOT NULL REFERENCES cars (id) ON DELETE CASCADE,
+    change TEXT NOT NULL,
+    price TEXT,
+    price_amount NUMERIC,
+    currency TEXT,
+    observed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS car_price_history_car_id_idx ON car_price_history (car_id, observed_at);
+
+CREATE OR REPLACE FUNCTION record_car_price() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO car_price_history (car_id, change, price, price_amount, currency, observed_at)
+    VALUES (
+        NEW.id,
+        CASE WHEN TG_OP = 'INSERT' THEN 'listed' ELSE 'repriced' END,
+        NEW.price, NEW.price_amount, NEW.currency,
+        CASE WHEN TG_OP = 'INSERT' THEN COALESCE(NEW.created_at, CURRENT_TIMESTAMP) ELSE CURRENT_TIMESTAMP END
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS cars_price_listed ON cars;
+CREATE TRIGGER cars_price_listed AFTER INSERT ON cars
+    FOR EACH ROW EXECUTE FUNCTION record_car_price();
+
+DROP TRIGGER IF EXISTS cars_price_repriced ON cars;
+CREATE TRIGGER cars_price_repriced AFTER UPDATE OF price, currency ON cars
+    FOR EACH ROW
+    WHEN (OLD.price_amount IS DISTINCT FROM NEW.price_amount OR OLD.currency IS DISTINCT FROM NEW.currency)
+    EXECUTE FUNCTION record_car_price();
+
+INSERT INTO car_price_history (car_id, change, price, price_amount, currency, observed_at)
+SELECT c.id, 'listed', c.price, c.price_amount, c.currency, COALESCE(c.created_at, CURRENT_TIMESTAMP)
+FROM cars c
+WHERE NOT EXISTS (SELECT 1 FROM car_price_history h WHERE h.car_id = c.id);
+
+-- One row per live listing, currency and week or month it was listed or
+-- repriced in, carrying its last price in that period. Trend queries take
+-- medians over these rows. Refreshed after every scrape job.
+CREATE MATERIALIZED VIEW IF NOT EXISTS price_trend_points AS
+SELECT
+    p.period,
+    date_trunc(p.period, h.observed_at AT TIME ZONE 'UTC') AS period_start,
+    h.car_id,
+    h.currency,
+    c.make,
+    c.model,
+    c.year_num,
+    (array_agg(h.price_amount ORDER BY h.observed_at DESC, h.id DESC))[1] AS price_amount,
+    bool_or(h.change = 'listed') AS listed
+FROM car_price_history h
+JOIN cars c ON c.id = h.car_id
+CROSS JOIN (VALUES ('week'), ('month')) AS p (period)
+WHERE c.deleted_at IS NULL AND h.price_amount IS NOT NULL
+GROUP BY p.period, period_start, h.car_id, h.currency, c.make, c.model, c.year_num;
+
+-- Needed for REFRESH MATERIALIZED VIEW CONCURRENTLY.
+CREATE UNIQUE INDEX IF NOT EXISTS price_trend_points_key ON price_trend_points (period, period_start, car_id, currency);
+CREATE INDEX IF NOT EXISTS price_trend_points_make_model_idx ON price_trend_points (period, make, model, year_num);
@@ -0,0 +1,14 @@
+-- How each live listing's price compares with its comparables: listings of
+-- the same make, model and currency within a few model years and a similar
+-- mileage. Rewritten as a whole after every scrape job.
+CREATE TABLE IF NOT EXISTS car_deal_scores (
+    car_id INTEGER PRIMARY KEY REFERENCES cars (id) ON DELETE CASCADE,
+    fair_price NUMERIC NOT NULL,
+    comparables INTEGER NOT NULL,
+    deal_score DOUBLE PRECISION NOT NULL,
+    deal_percentile DOUBLE PRECISION NOT NULL,
+    scored_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS car_deal_scores_score_idx ON car_deal_scores (deal_score DESC);
+CREATE INDEX IF NOT EXISTS car_deal_scores_percentile_idx ON car_deal_scores (deal_percentile DESC);
@@ -0,0 +1,10 @@
+-- Every trained valuation model, newest version in use. params is the
+-- serialized model and metrics its held-out evaluation.
+CREATE TABLE IF NOT EXISTS valuation_models (
+    version SERIAL PRIMARY KEY,
+    currency TEXT NOT NULL,
+    trained_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    training_rows INTEGER NOT NULL,
+    metrics JSONB NOT NULL,
+    params JSONB NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS fraud_reviews;
+DROP TABLE IF EXISTS fraud_flags;
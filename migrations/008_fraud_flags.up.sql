@@ -0,0 +1,22 @@
+-- Fraud signals the rules engine found on live listings, one row per rule
+-- that fired. Replaced after every scrape job; a flag raised again keeps its
+-- detected_at.
+CREATE TABLE IF NOT EXISTS fraud_flags (
+    car_id INTEGER NOT NULL REFERENCES cars (id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    weight DOUBLE PRECISION NOT NULL,
+    reason TEXT NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (car_id, rule)
+);
+
+-- A moderator's verdict on a listing. Confirmed scams are hidden from
+-- listing queries; reviews survive re-detection.
+CREATE TABLE IF NOT EXISTS fraud_reviews (
+    car_id INTEGER PRIMARY KEY REFERENCES cars (id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('confirmed', 'dismissed')),
+    note TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS fraud_reviews_confirmed_idx ON fraud_reviews (car_id) WHERE status = 'confirmed';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS cars_seller_created_idx;
+
+ALTER TABLE cars DROP COLUMN IF EXISTS seller;
@@ -0,0 +1,7 @@
+-- The Marketplace profile ID of the seller, when the scraper looked it up.
+-- Empty when unknown, so existing writers and scans need no NULL handling.
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS seller TEXT NOT NULL DEFAULT '';
+
+-- Serves the fraud rule counting a seller's recent listings.
+CREATE INDEX IF NOT EXISTS cars_seller_created_idx ON cars (seller, created_at)
+    WHERE deleted_at IS NULL AND seller <> '';
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS cars_price_repriced ON cars;
+CREATE TRIGGER cars_price_repriced AFTER UPDATE OF price, currency ON cars
+    FOR EACH ROW
+    WHEN (OLD.price_amount IS DISTINCT FROM NEW.price_amount OR OLD.currency IS DISTINCT FROM NEW.currency)
+    EXECUTE FUNCTION record_car_price();
@@ -0,0 +1,21 @@
+-- Record a repricing only when the amount changes. Backfills that rewrite
+-- the price text or fill in a currency left the amount alone, yet each one
+-- added a 'repriced' row that counted towards the trend listings. A price
+-- first parsed from a row that had none is not a repricing either.
+DROP TRIGGER IF EXISTS cars_price_repriced ON cars;
+CREATE TRIGGER cars_price_repriced AFTER UPDATE OF price ON cars
+    FOR EACH ROW
+    WHEN (OLD.price_amount IS NOT NULL AND OLD.price_amount IS DISTINCT FROM NEW.price_amount)
+    EXECUTE FUNCTION record_car_price();
+
+-- Drop the rows the old trigger recorded for such updates.
+DELETE FROM car_price_history h
+USING (
+    SELECT id, lag(price_amount) OVER (PARTITION BY car_id ORDER BY observed_at, id) AS previous
+    FROM car_price_history
+) p
+WHERE h.id = p.id
+  AND h.change = 'repriced'
+  AND (p.previous IS NULL OR p.previous = h.price_amount);
+
+REFRESH MATERIALIZED VIEW price_trend_points;
@@ -0,0 +1,79 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_add_index.up.sql":      {Data: []byte("CREATE INDEX i ON t (c);")},
+		"010_add_index.down.sql":    {Data: []byte("DROP INDEX i;")},
+		"002_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c INT);")},
+		"002_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"README.md":                 {Data: []byte("not a migration")},
+	}
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 2 || migrations[1].Version != 10 {
+		t.Fatalf("loaded %+v, want versions 2 and 10 in order", migrations)
+	}
+	if m := migrations[0]; m.Name != "create_table" || m.Down != "DROP TABLE t;" || len(m.Checksum) != 64 {
+		t.Errorf("migration 2 = %+v", m)
+	}
+	if migrations[0].Checksum == migrations[1].Checksum {
+		t.Error("different up scripts have the same checksum")
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"003_one.up.sql":   {Data: []byte("SELECT 1;")},
+				"003_other.up.sql": {Data: []byte("SELECT 2;")},
+			},
+			want: "conflicting names",
+		},
+		{
+			name: "down without up",
+			fsys: fstest.MapFS{"004_orphan.down.sql": {Data: []byte("SELECT 1;")}},
+			want: "no up script",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := load(tt.fsys); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// The embedded migrations are numbered without gaps and each can be
+// reverted.
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, migration := range m.migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %d_%s is at position %d", migration.Version, migration.Name, i+1)
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			t.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+	}
+	if m.Latest() != len(m.migrations) {
+		t.Errorf("Latest() = %d, want %d", m.Latest(), len(m.migrations))
+	}
+}
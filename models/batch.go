@@ -0,0 +1,35 @@
+package models
+
+// BatchMode selects how a batch reacts to a failing operation.
+type BatchMode string
+
+const (
+	// BatchAtomic runs every operation in one transaction and rolls all of
+	// them back if any fails.
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort runs operations independently and reports each result.
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchRequest is the body of POST /api/v1/cars:batch.
+type BatchRequest struct {
+	Mode       BatchMode        `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Operations []BatchOperation `json:"operations" binding:"required,min=1"`
+}
+
+// BatchOperation is one create, update or delete. Version, when set, makes
+// an update conditional like If-Match does.
+type BatchOperation struct {
+	Op      BatchOp     `json:"op" binding:"required,oneof=create update delete"`
+	ID      int         `json:"id" binding:"required_unless=Op create,omitempty,min=1"`
+	Version int         `json:"version" binding:"omitempty,min=1"`
+	Car     *CarRequest `json:"car" binding:"required_unless=Op delete"`
+}
@@ -3,14 +3,19 @@ package models
 import "time"
 
 type Car struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Price     string    `json:"price"`
-	Currency  string    `json:"currency"`
-	Year      string    `json:"year"`
-	Mileage   string    `json:"mileage"`
-	Location  string    `json:"location"`
-	Link      string    `json:"link"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int        `json:"id"`
+	Title     string     `json:"title"`
+	Price     string     `json:"price"`
+	Currency  string     `json:"currency"`
+	Year      string     `json:"year"`
+	Mileage   string     `json:"mileage"`
+	Location  string     `json:"location"`
+	Link      string     `json:"link"`
+	Seller    string     `json:"seller,omitempty"`
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Deal is set on listings read back with their latest deal score.
+	Deal *DealScore `json:"deal,omitempty"`
 }
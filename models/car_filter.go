@@ -0,0 +1,46 @@
+package models
+
+// DeletedFilter selects listings by their soft-delete state.
+type DeletedFilter string
+
+const (
+	// DeletedExclude hides soft-deleted listings. It is the default.
+	DeletedExclude DeletedFilter = "exclude"
+	// DeletedInclude lists live and soft-deleted listings together.
+	DeletedInclude DeletedFilter = "include"
+	// DeletedOnly lists the trash.
+	DeletedOnly DeletedFilter = "only"
+)
+
+// ScamFilter selects listings by whether a moderator confirmed them as
+// scams.
+type ScamFilter string
+
+const (
+	// ScamsExclude hides confirmed scams. It is the default.
+	ScamsExclude ScamFilter = "exclude"
+	// ScamsInclude lists confirmed scams with everything else.
+	ScamsInclude ScamFilter = "include"
+	// ScamsOnly lists confirmed scams.
+	ScamsOnly ScamFilter = "only"
+)
+
+// CarSort orders a listing query.
+type CarSort string
+
+const (
+	// SortNewest lists the most recently stored first. It is the default.
+	SortNewest CarSort = "newest"
+	// SortDealScore lists the furthest below their fair price first.
+	SortDealScore CarSort = "deal_score"
+	// SortDealPercentile lists the cheapest among their comparables first.
+	SortDealPercentile CarSort = "deal_percentile"
+)
+
+// CarFilter narrows a listing query. The zero value lists live cars that
+// are not confirmed scams, newest first.
+type CarFilter struct {
+	Deleted DeletedFilter
+	Scams   ScamFilter
+	Sort    CarSort
+}
@@ -0,0 +1,43 @@
+package models
+
+// CarRequest is the body accepted when creating or replacing a car. It is kept
+// apart from Car so clients cannot set the ID or timestamps.
+type CarRequest struct {
+	Title    string `json:"title" binding:"required,max=255"`
+	Price    string `json:"price" binding:"required,car_price"`
+	Currency string `json:"currency" binding:"omitempty,oneof=PHP USD"`
+	Year     string `json:"year" binding:"omitempty,car_year"`
+	Mileage  string `json:"mileage" binding:"omitempty,max=64"`
+	Location string `json:"location" binding:"omitempty,max=255"`
+	Link     string `json:"link" binding:"required,marketplace_item"`
+	Seller   string `json:"seller" binding:"omitempty,max=255"`
+}
+
+// NewCarRequest returns the editable fields of car, the document PATCH
+// requests are merged into.
+func NewCarRequest(car *Car) CarRequest {
+	return CarRequest{
+		Title:    car.Title,
+		Price:    car.Price,
+		Currency: car.Currency,
+		Year:     car.Year,
+		Mileage:  car.Mileage,
+		Location: car.Location,
+		Link:     car.Link,
+		Seller:   car.Seller,
+	}
+}
+
+// Car copies the request onto a new Car.
+func (r *CarRequest) Car() Car {
+	return Car{
+		Title:    r.Title,
+		Price:    r.Price,
+		Currency: r.Currency,
+		Year:     r.Year,
+		Mileage:  r.Mileage,
+		Location: r.Location,
+		Link:     r.Link,
+		Seller:   r.Seller,
+	}
+}
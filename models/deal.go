@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// DealScore rates a listing's price against its comparables. Score is how
+// far below the fair price it is asking, as a fraction of it: 0.2 is 20%
+// under, negative is over. Percentile is the share of comparables asking
+// more, so 1 is the cheapest.
+type DealScore struct {
+	FairPrice   float64   `json:"fair_price"`
+	Comparables int       `json:"comparables"`
+	Score       float64   `json:"score"`
+	Percentile  float64   `json:"percentile"`
+	ScoredAt    time.Time `json:"scored_at"`
+}
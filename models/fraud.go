@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// FraudFlag is one fraud signal found on a listing. Weight adds to the
+// listing's fraud score.
+type FraudFlag struct {
+	CarID      int       `json:"-"`
+	Rule       string    `json:"rule"`
+	Weight     float64   `json:"weight"`
+	Reason     string    `json:"reason"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// FraudCandidate is a live listing as the fraud rules see it: with its deal
+// score, how many other live listings share its title and how many live
+// listings its seller posted recently.
+type FraudCandidate struct {
+	Car               Car
+	TitleReuse        int
+	SellerRecentPosts int
+}
+
+type FraudReviewStatus string
+
+const (
+	// FraudPending is a flagged listing no moderator has reviewed.
+	FraudPending   FraudReviewStatus = "pending"
+	FraudConfirmed FraudReviewStatus = "confirmed"
+	FraudDismissed FraudReviewStatus = "dismissed"
+)
+
+type FraudReview struct {
+	Status     FraudReviewStatus `json:"status"`
+	Note       string            `json:"note,omitempty"`
+	ReviewedAt time.Time         `json:"reviewed_at"`
+}
+
+// FraudReviewRequest is a moderator's note on a confirm or dismiss.
+type FraudReviewRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// FraudCase is a flagged listing for moderators, with its total score.
+type FraudCase struct {
+	Car    Car          `json:"car"`
+	Score  float64      `json:"score"`
+	Flags  []FraudFlag  `json:"flags"`
+	Review *FraudReview `json:"review,omitempty"`
+}
+
+// FraudCaseQuery selects the cases to review.
+type FraudCaseQuery struct {
+	Status   FraudReviewStatus
+	MinScore float64
+	Limit    int
+}
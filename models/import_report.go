@@ -0,0 +1,47 @@
+package models
+
+import "github.com/yourusername/car-listing-service/apperror"
+
+type ImportStatus string
+
+const (
+	ImportAccepted  ImportStatus = "accepted"
+	ImportDuplicate ImportStatus = "duplicate"
+	ImportRejected  ImportStatus = "rejected"
+)
+
+// ImportRow reports what happened to one data line of an import. Line
+// counts from 1 and includes the CSV header.
+type ImportRow struct {
+	Line    int                   `json:"line"`
+	Status  ImportStatus          `json:"status"`
+	Link    string                `json:"link,omitempty"`
+	Reason  string                `json:"reason,omitempty"`
+	Message string                `json:"message,omitempty"`
+	Errors  []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// ImportReport summarises an import. In a dry run accepted rows are the ones
+// that would have been inserted.
+type ImportReport struct {
+	DryRun     bool        `json:"dry_run"`
+	Total      int         `json:"total"`
+	Accepted   int         `json:"accepted"`
+	Duplicates int         `json:"duplicates"`
+	Rejected   int         `json:"rejected"`
+	Rows       []ImportRow `json:"rows"`
+}
+
+// Add records row and updates the totals.
+func (r *ImportReport) Add(row ImportRow) {
+	r.Total++
+	switch row.Status {
+	case ImportAccepted:
+		r.Accepted++
+	case ImportDuplicate:
+		r.Duplicates++
+	case ImportRejected:
+		r.Rejected++
+	}
+	r.Rows = append(r.Rows, row)
+}
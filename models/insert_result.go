@@ -0,0 +1,27 @@
+package models
+
+// Reasons a row in a batch insert was skipped.
+const (
+	SkipDuplicateLink = "duplicate_link"
+	SkipBlockedLink   = "blocked_link"
+	// SkipKnownLink marks links found stored, trashed or blocked before the
+	// insert was attempted.
+	SkipKnownLink = "known_link"
+)
+
+// RowResult explains what happened to one row of a batch insert. Index is
+// the row's position in the batch.
+type RowResult struct {
+	Index   int    `json:"index"`
+	Link    string `json:"link"`
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+}
+
+// InsertResult tallies a batch insert. Skipped rows were already stored or
+// blocked; failed rows were rejected by the database and not stored.
+type InsertResult struct {
+	Inserted int         `json:"inserted"`
+	Skipped  []RowResult `json:"skipped,omitempty"`
+	Failed   []RowResult `json:"failed,omitempty"`
+}
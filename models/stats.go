@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// StatsDimension is a listing attribute statistics can be grouped by.
+type StatsDimension string
+
+const (
+	StatsByMake     StatsDimension = "make"
+	StatsByModel    StatsDimension = "model"
+	StatsByYear     StatsDimension = "year"
+	StatsByLocation StatsDimension = "location"
+)
+
+var StatsDimensions = []StatsDimension{StatsByMake, StatsByModel, StatsByYear, StatsByLocation}
+
+// StatsQuery selects the live listings to aggregate and how to group them.
+// Make and model match the lowercased words parsed from the title; location
+// matches any listing whose location contains it.
+type StatsQuery struct {
+	GroupBy  []StatsDimension
+	Make     string
+	Model    string
+	Location string
+	Currency string
+	YearMin  int
+	YearMax  int
+	Since    *time.Time
+	Until    *time.Time
+	Limit    int
+}
+
+// Distribution summarises a numeric attribute over the listings that have
+// it. The statistics are nil when Count is zero.
+type Distribution struct {
+	Count  int      `json:"count"`
+	Min    *float64 `json:"min"`
+	Mean   *float64 `json:"mean"`
+	P10    *float64 `json:"p10"`
+	P25    *float64 `json:"p25"`
+	Median *float64 `json:"median"`
+	P75    *float64 `json:"p75"`
+	P90    *float64 `json:"p90"`
+	Max    *float64 `json:"max"`
+}
+
+// HistogramBucket counts values in [Min, Max). The last bucket has no Max.
+type HistogramBucket struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int      `json:"count"`
+}
+
+type MileageStats struct {
+	Distribution
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// StatsGroup is one row of grouped statistics. Only the attributes named in
+// the query's GroupBy are set.
+type StatsGroup struct {
+	Make     string       `json:"make,omitempty"`
+	Model    string       `json:"model,omitempty"`
+	Year     int          `json:"year,omitempty"`
+	Location string       `json:"location,omitempty"`
+	Count    int          `json:"count"`
+	Price    Distribution `json:"price"`
+	Mileage  MileageStats `json:"mileage_km"`
+}
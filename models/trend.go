@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// TrendInterval is the period a price trend is bucketed by.
+type TrendInterval string
+
+const (
+	TrendWeekly  TrendInterval = "week"
+	TrendMonthly TrendInterval = "month"
+)
+
+// TrendQuery selects the listings of one make, and optionally one model and
+// year range, whose prices are charted.
+type TrendQuery struct {
+	Interval TrendInterval
+	Make     string
+	Model    string
+	Currency string
+	YearMin  int
+	YearMax  int
+	Since    *time.Time
+	Until    *time.Time
+}
+
+// TrendPoint describes one period. Listings counts the listings first
+// stored or repriced in it, NewListings just the first; MedianPrice is over
+// each one's last asking price in the period.
+type TrendPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	Listings    int       `json:"listings"`
+	NewListings int       `json:"new_listings"`
+	MedianPrice float64   `json:"median_price"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ValuationRequest describes a car to price. Make, model and location are
+// matched, ignoring case, against the words parsed from stored listings.
+type ValuationRequest struct {
+	Make      string   `json:"make" binding:"required,max=64"`
+	Model     string   `json:"model" binding:"omitempty,max=64"`
+	Year      int      `json:"year" binding:"required,car_year"`
+	MileageKm *float64 `json:"mileage_km" binding:"omitempty,min=0"`
+	Location  string   `json:"location" binding:"omitempty,max=255"`
+}
+
+// ValuationSample is one stored listing used for training.
+type ValuationSample struct {
+	Make      string
+	Model     string
+	Year      int
+	MileageKm *float64
+	Location  string
+	Price     float64
+}
+
+// ValuationMatch reports which of the request's categories the model saw
+// often enough in training to price. Unmatched ones fall back to the
+// average.
+type ValuationMatch struct {
+	Make     bool `json:"make"`
+	Model    bool `json:"model"`
+	Location bool `json:"location"`
+}
+
+type Valuation struct {
+	PredictedPrice float64        `json:"predicted_price"`
+	Low            float64        `json:"low"`
+	High           float64        `json:"high"`
+	Confidence     float64        `json:"confidence"`
+	Currency       string         `json:"currency"`
+	Matched        ValuationMatch `json:"matched"`
+	ModelVersion   int            `json:"model_version"`
+	TrainedAt      time.Time      `json:"trained_at"`
+}
+
+// ValuationMetrics evaluates a model on listings held out of training.
+// Errors are in price units except MAPE, a fraction.
+type ValuationMetrics struct {
+	Lambda    float64 `json:"lambda"`
+	TrainRows int     `json:"train_rows"`
+	TestRows  int     `json:"test_rows"`
+	MAE       float64 `json:"mae"`
+	RMSE      float64 `json:"rmse"`
+	MAPE      float64 `json:"mape"`
+	R2        float64 `json:"r2"`
+}
+
+// ValuationModelInfo describes one stored model version.
+type ValuationModelInfo struct {
+	Version      int              `json:"version"`
+	Currency     string           `json:"currency"`
+	TrainedAt    time.Time        `json:"trained_at"`
+	TrainingRows int              `json:"training_rows"`
+	Metrics      ValuationMetrics `json:"metrics"`
+}
+
+// ValuationModelRecord is a stored model with its serialized parameters.
+type ValuationModelRecord struct {
+	ValuationModelInfo
+	Params json.RawMessage
+}
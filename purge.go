@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/services"
+)
+
+func runPurge(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ContinueOnError)
+	retention := flags.Duration("retention", cfg.TrashRetention, "purge cars deleted longer ago than this")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close(context.Background())
+
+	purged, err := a.service.PurgeDeletedCars(ctx, *retention)
+	fmt.Fprintf(os.Stderr, "purged %d listings\n", purged)
+	return err
+}
+
+// purgeTrash purges the trash every interval until ctx is cancelled.
+func purgeTrash(ctx context.Context, service services.CarService, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := service.PurgeDeletedCars(ctx, retention); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "failed to purge deleted cars", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/services"
+)
+
+type purgeCounter struct {
+	services.CarService
+	retentions chan time.Duration
+}
+
+func (s *purgeCounter) PurgeDeletedCars(ctx context.Context, retention time.Duration) (int, error) {
+	s.retentions <- retention
+	return 0, nil
+}
+
+func TestPurgeTrashRunsUntilCancelled(t *testing.T) {
+	service := &purgeCounter{retentions: make(chan time.Duration)}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		purgeTrash(ctx, service, time.Millisecond, 48*time.Hour)
+		close(done)
+	}()
+
+	// The first purge runs at once, the next on the ticker.
+	for i := 0; i < 3; i++ {
+		if retention := <-service.retentions; retention != 48*time.Hour {
+			t.Errorf("purge %d: retention = %v, want 48h", i+1, retention)
+		}
+	}
+	cancel()
+	// Drain a purge that raced the cancellation.
+	for {
+		select {
+		case <-service.retentions:
+			continue
+		case <-done:
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("purgeTrash did not return after cancellation")
+		}
+	}
+}
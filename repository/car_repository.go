@@ -1,96 +1,269 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"time"
 
+	"github.com/yourusername/car-listing-service/apperror"
 	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/tracing"
 	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/yourusername/car-listing-service/repository")
+
 type CarRepository interface {
-	GetAll() ([]models.Car, error)
-	GetByID(id int) (*models.Car, error)
-	Create(car *models.Car) error
-	Update(car *models.Car) error
-	Delete(id int) error
-	FindExistingLinks(links []string) (map[string]bool, error)
-	InsertBatch(cars []models.Car) (int, error)
+	GetAll(ctx context.Context, filter models.CarFilter) ([]models.Car, error)
+	// Each streams matching cars to fn through a server-side cursor.
+	Each(ctx context.Context, filter models.CarFilter, fn func(car *models.Car) error) error
+	GetByID(ctx context.Context, id int) (*models.Car, error)
+	Create(ctx context.Context, car *models.Car) error
+	// Update saves car and bumps its version. A non-zero car.Version makes
+	// the update conditional on the stored version still matching.
+	Update(ctx context.Context, car *models.Car) error
+	// Delete moves a car to the trash. Trashed cars keep their link, so
+	// scrapes do not re-insert them.
+	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) (*models.Car, error)
+	// Purge removes cars trashed before cutoff and blocks their links.
+	Purge(ctx context.Context, cutoff time.Time) (int, error)
+	// FindExistingLinks reports which links are stored, trashed or blocked.
+	FindExistingLinks(ctx context.Context, links []string) (map[string]bool, error)
+	InsertBatch(ctx context.Context, cars []models.Car) (models.InsertResult, error)
+	// CopyInsert is a faster InsertBatch for large batches that reports
+	// skipped rows but fails as a whole if any row is rejected.
+	CopyInsert(ctx context.Context, cars []models.Car) (models.InsertResult, error)
+	// Stats aggregates prices and mileages of live listings per group.
+	Stats(ctx context.Context, query models.StatsQuery) ([]models.StatsGroup, error)
+	// PriceTrends charts median prices per week or month as of the last
+	// RefreshPriceTrends.
+	PriceTrends(ctx context.Context, query models.TrendQuery) ([]models.TrendPoint, error)
+	RefreshPriceTrends(ctx context.Context) error
+	// ScoreDeals rates every live listing against its comparables, replacing
+	// the previous scores, and returns how many could be scored.
+	ScoreDeals(ctx context.Context) (int, error)
+	ValuationSamples(ctx context.Context, currency string) ([]models.ValuationSample, error)
+	SaveValuationModel(ctx context.Context, model *models.ValuationModelRecord) error
+	// LatestValuationModel returns the newest model, leaving out its
+	// parameters if it is still knownVersion.
+	LatestValuationModel(ctx context.Context, currency string, knownVersion int) (*models.ValuationModelRecord, error)
+	ValuationModels(ctx context.Context, currency string) ([]models.ValuationModelInfo, error)
+	// FraudCandidates returns the live listings the fraud rules check,
+	// counting each seller's listings created since sellerSince.
+	FraudCandidates(ctx context.Context, sellerSince time.Time) ([]models.FraudCandidate, error)
+	// ReplaceFraudFlags swaps every stored flag for flags. Reviews are kept.
+	ReplaceFraudFlags(ctx context.Context, flags []models.FraudFlag) error
+	FraudCases(ctx context.Context, query models.FraudCaseQuery) ([]models.FraudCase, error)
+	// ReviewFraud records a moderator's verdict on a live listing.
+	ReviewFraud(ctx context.Context, carID int, status models.FraudReviewStatus, note string) (*models.FraudReview, error)
+	// WithTx runs fn with a repository bound to a single transaction.
+	WithTx(ctx context.Context, fn func(repo CarRepository) error) error
+}
+
+// Timeouts bound each kind of repository call. A zero value disables the
+// bound and leaves only the caller's context.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+	Batch time.Duration
 }
 
 type carRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	tx       *sql.Tx
+	timeouts Timeouts
+}
+
+func NewCarRepository(db *sql.DB, timeouts Timeouts) CarRepository {
+	return &carRepository{db: db, timeouts: timeouts}
+}
+
+// endSpan maps err to a domain error and closes the span. Defer it with a
+// pointer to the method's named error result.
+func endSpan(span trace.Span, err *error) {
+	*err = mapError(*err, carNotFound())
+	tracing.End(span, *err)
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// startSpan opens a client span for one repository call against the cars table.
+func startSpan(ctx context.Context, name, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", operation),
+		attribute.String("db.sql.table", "cars"),
+	)
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// carColumns is the column list scanCar expects. The first schema left the
+// text columns other than title and link nullable, and rows scraped before
+// currency and year were derived have them NULL, so they read as "".
+const carColumns = "id, title, COALESCE(price, ''), COALESCE(currency, ''), COALESCE(year, ''), " +
+	"COALESCE(location, ''), COALESCE(mileage, ''), link, seller, version, created_at, updated_at, deleted_at"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
-func NewCarRepository(db *sql.DB) CarRepository {
-	return &carRepository{db: db}
+func scanCar(row rowScanner, car *models.Car) error {
+	return row.Scan(
+		&car.ID, &car.Title, &car.Price, &car.Currency, &car.Year,
+		&car.Location, &car.Mileage, &car.Link, &car.Seller, &car.Version,
+		&car.CreatedAt, &car.UpdatedAt, &car.DeletedAt,
+	)
 }
 
-func (r *carRepository) GetAll() ([]models.Car, error) {
-	query := "SELECT id, title, price, currency, year, location, mileage, link, created_at, updated_at FROM cars ORDER BY created_at DESC"
-	rows, err := r.db.Query(query)
+// scoredCarsFrom joins each car to its deal score, which scanScoredCar reads
+// after carColumns.
+const scoredCarsFrom = " FROM cars LEFT JOIN car_deal_scores ON car_id = id"
+
+const dealColumns = "fair_price, comparables, deal_score, deal_percentile, scored_at"
+
+func scanScoredCar(row rowScanner, car *models.Car) error {
+	var fairPrice, score, percentile sql.NullFloat64
+	var comparables sql.NullInt64
+	var scoredAt sql.NullTime
+	err := row.Scan(
+		&car.ID, &car.Title, &car.Price, &car.Currency, &car.Year,
+		&car.Location, &car.Mileage, &car.Link, &car.Seller, &car.Version,
+		&car.CreatedAt, &car.UpdatedAt, &car.DeletedAt,
+		&fairPrice, &comparables, &score, &percentile, &scoredAt,
+	)
+	if err != nil || !scoredAt.Valid {
+		return err
+	}
+	car.Deal = &models.DealScore{
+		FairPrice:   fairPrice.Float64,
+		Comparables: int(comparables.Int64),
+		Score:       score.Float64,
+		Percentile:  percentile.Float64,
+		ScoredAt:    scoredAt.Time,
+	}
+	return nil
+}
+
+func (r *carRepository) GetAll(ctx context.Context, filter models.CarFilter) (cars []models.Car, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.GetAll", "SELECT", attribute.String("filter.deleted", string(filter.Deleted)))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	where, args := whereClause(filter)
+	query := "SELECT " + carColumns + ", " + dealColumns + scoredCarsFrom + where + orderClause(filter)
+	rows, err := r.conn().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var cars []models.Car
 	for rows.Next() {
 		var car models.Car
-		if err := rows.Scan(&car.ID, &car.Title, &car.Price, &car.Currency, &car.Year, &car.Location, &car.Mileage, &car.Link, &car.CreatedAt, &car.UpdatedAt); err != nil {
+		if err := scanScoredCar(rows, &car); err != nil {
 			return nil, err
 		}
 		cars = append(cars, car)
 	}
 
+	span.SetAttributes(attribute.Int("db.rows", len(cars)))
 	return cars, rows.Err()
 }
 
-func (r *carRepository) GetByID(id int) (*models.Car, error) {
-	query := "SELECT id, title, price, currency, year, location, mileage, link, created_at, updated_at FROM cars WHERE id = $1"
+func (r *carRepository) GetByID(ctx context.Context, id int) (_ *models.Car, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.GetByID", "SELECT", attribute.Int("car.id", id))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	query := "SELECT " + carColumns + ", " + dealColumns + scoredCarsFrom + " WHERE id = $1 AND deleted_at IS NULL"
 	var car models.Car
-	err := r.db.QueryRow(query, id).Scan(
-		&car.ID, &car.Title, &car.Price, &car.Currency, &car.Year,
-		&car.Location, &car.Mileage, &car.Link, &car.CreatedAt, &car.UpdatedAt,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
+	if err := scanScoredCar(r.conn().QueryRowContext(ctx, query, id), &car); err != nil {
 		return nil, err
 	}
 	return &car, nil
 }
 
-func (r *carRepository) Create(car *models.Car) error {
+func (r *carRepository) Create(ctx context.Context, car *models.Car) (err error) {
+	ctx, span := startSpan(ctx, "CarRepository.Create", "INSERT")
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
 	query := `
-		INSERT INTO cars (title, price, currency, year, location, mileage, link)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at, updated_at
+		INSERT INTO cars (title, price, currency, year, location, mileage, link, seller)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version, created_at, updated_at
 	`
-	return r.db.QueryRow(
+	return r.conn().QueryRowContext(
+		ctx,
 		query,
-		car.Title, car.Price, car.Currency, car.Year, car.Location, car.Mileage, car.Link,
-	).Scan(&car.ID, &car.CreatedAt, &car.UpdatedAt)
+		car.Title, car.Price, car.Currency, car.Year, car.Location, car.Mileage, car.Link, car.Seller,
+	).Scan(&car.ID, &car.Version, &car.CreatedAt, &car.UpdatedAt)
 }
 
-func (r *carRepository) Update(car *models.Car) error {
+func (r *carRepository) Update(ctx context.Context, car *models.Car) (err error) {
+	ctx, span := startSpan(ctx, "CarRepository.Update", "UPDATE", attribute.Int("car.id", car.ID))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
 	query := `
 		UPDATE cars
 		SET title = $1, price = $2, currency = $3, year = $4,
-		    location = $5, mileage = $6, link = $7, updated_at = NOW()
-		WHERE id = $8
-		RETURNING updated_at
+		    location = $5, mileage = $6, link = $7, seller = $8,
+		    version = version + 1, updated_at = NOW()
+		WHERE id = $9 AND deleted_at IS NULL AND ($10 = 0 OR version = $10)
+		RETURNING version, created_at, updated_at
 	`
-	return r.db.QueryRow(
+	err = r.conn().QueryRowContext(
+		ctx,
 		query,
 		car.Title, car.Price, car.Currency, car.Year,
-		car.Location, car.Mileage, car.Link, car.ID,
-	).Scan(&car.UpdatedAt)
+		car.Location, car.Mileage, car.Link, car.Seller, car.ID, car.Version,
+	).Scan(&car.Version, &car.CreatedAt, &car.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) && car.Version != 0 {
+		return r.versionConflict(ctx, car.ID, car.Version)
+	}
+	return err
+}
+
+// versionConflict explains why a conditional update matched no row: either
+// the car is gone or someone else saved it first.
+func (r *carRepository) versionConflict(ctx context.Context, id, expected int) error {
+	var current int
+	if err := r.conn().QueryRowContext(ctx, "SELECT version FROM cars WHERE id = $1 AND deleted_at IS NULL", id).Scan(&current); err != nil {
+		return err
+	}
+	return apperror.PreconditionFailed("version_mismatch", "The car was modified by another request").
+		WithDetails(map[string]interface{}{
+			"expected_version": expected,
+			"current_version":  current,
+		})
 }
 
-func (r *carRepository) Delete(id int) error {
-	query := "DELETE FROM cars WHERE id = $1"
-	result, err := r.db.Exec(query, id)
+func (r *carRepository) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := startSpan(ctx, "CarRepository.Delete", "UPDATE", attribute.Int("car.id", id))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	query := `
+		UPDATE cars
+		SET deleted_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	result, err := r.conn().ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -104,13 +277,68 @@ func (r *carRepository) Delete(id int) error {
 	return nil
 }
 
-func (r *carRepository) FindExistingLinks(links []string) (map[string]bool, error) {
+// Restore takes a car out of the trash. Restoring a live car is a no-op.
+func (r *carRepository) Restore(ctx context.Context, id int) (_ *models.Car, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.Restore", "UPDATE", attribute.Int("car.id", id))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	query := `
+		UPDATE cars
+		SET deleted_at = NULL,
+		    version = version + CASE WHEN deleted_at IS NULL THEN 0 ELSE 1 END,
+		    updated_at = CASE WHEN deleted_at IS NULL THEN updated_at ELSE NOW() END
+		WHERE id = $1
+		RETURNING ` + carColumns
+	var car models.Car
+	if err := scanCar(r.conn().QueryRowContext(ctx, query, id), &car); err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+func (r *carRepository) Purge(ctx context.Context, cutoff time.Time) (_ int, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.Purge", "DELETE", attribute.String("purge.cutoff", cutoff.Format(time.RFC3339)))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Batch)
+	defer cancel()
+
+	query := `
+		WITH purged AS (
+			DELETE FROM cars WHERE deleted_at < $1 RETURNING link
+		), blocked AS (
+			INSERT INTO blocked_links (link, reason)
+			SELECT link, 'purged' FROM purged
+			ON CONFLICT (link) DO NOTHING
+		)
+		SELECT count(*) FROM purged
+	`
+	var purged int
+	if err := r.conn().QueryRowContext(ctx, query, cutoff).Scan(&purged); err != nil {
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Int("db.rows", purged))
+	return purged, nil
+}
+
+func (r *carRepository) FindExistingLinks(ctx context.Context, links []string) (_ map[string]bool, err error) {
 	if len(links) == 0 {
 		return make(map[string]bool), nil
 	}
 
-	query := "SELECT link FROM cars WHERE link = ANY($1)"
-	rows, err := r.db.Query(query, pq.Array(links))
+	ctx, span := startSpan(ctx, "CarRepository.FindExistingLinks", "SELECT", attribute.Int("links", len(links)))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	query := `
+		SELECT link FROM cars WHERE link = ANY($1)
+		UNION
+		SELECT link FROM blocked_links WHERE link = ANY($1)
+	`
+	rows, err := r.conn().QueryContext(ctx, query, pq.Array(links))
 	if err != nil {
 		return nil, err
 	}
@@ -128,41 +356,104 @@ func (r *carRepository) FindExistingLinks(links []string) (map[string]bool, erro
 	return existingLinks, rows.Err()
 }
 
-func (r *carRepository) InsertBatch(cars []models.Car) (int, error) {
+// InsertBatch inserts cars one row at a time inside a single transaction.
+// Each row runs under a savepoint, so a row the database rejects is rolled
+// back and reported without aborting the rest of the batch. Rows whose link
+// is already stored or blocked are skipped.
+func (r *carRepository) InsertBatch(ctx context.Context, cars []models.Car) (result models.InsertResult, err error) {
 	if len(cars) == 0 {
-		return 0, nil
+		return result, nil
 	}
 
-	tx, err := r.db.Begin()
-	if err != nil {
-		return 0, err
-	}
-	defer tx.Rollback()
+	ctx, span := startSpan(ctx, "CarRepository.InsertBatch", "INSERT", attribute.Int("batch.size", len(cars)))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Batch)
+	defer cancel()
 
-	stmt, err := tx.Prepare(`
-		INSERT INTO cars (title, price, location, mileage, link)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (link) DO NOTHING
-	`)
-	if err != nil {
-		return 0, err
-	}
-	defer stmt.Close()
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		result = models.InsertResult{}
 
-	insertedCount := 0
-	for _, car := range cars {
-		result, err := stmt.Exec(car.Title, car.Price, car.Location, car.Mileage, car.Link)
+		blocked, err := blockedLinks(ctx, tx, cars)
 		if err != nil {
-			continue
+			return err
 		}
 
-		rows, _ := result.RowsAffected()
-		insertedCount += int(rows)
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO cars (title, price, currency, year, location, mileage, link, seller)
+			SELECT $1, $2, $3, $4, $5, $6, $7, $8
+			WHERE NOT EXISTS (SELECT 1 FROM blocked_links WHERE link = $7)
+			ON CONFLICT (link) DO NOTHING
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for i, car := range cars {
+			if blocked[car.Link] {
+				result.Skipped = append(result.Skipped, models.RowResult{Index: i, Link: car.Link, Reason: models.SkipBlockedLink})
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT insert_row"); err != nil {
+				return err
+			}
+
+			res, err := stmt.ExecContext(ctx, car.Title, car.Price, car.Currency, car.Year, car.Location, car.Mileage, car.Link, car.Seller)
+			if err != nil {
+				if fatal(ctx, err) {
+					return err
+				}
+				if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT insert_row"); rbErr != nil {
+					return rbErr
+				}
+				reason, message := rowFailure(err)
+				result.Failed = append(result.Failed, models.RowResult{Index: i, Link: car.Link, Reason: reason, Message: message})
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT insert_row"); err != nil {
+				return err
+			}
+
+			if rows, _ := res.RowsAffected(); rows > 0 {
+				result.Inserted++
+			} else {
+				result.Skipped = append(result.Skipped, models.RowResult{Index: i, Link: car.Link, Reason: models.SkipDuplicateLink})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.InsertResult{}, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return 0, err
+	span.SetAttributes(
+		attribute.Int("batch.inserted", result.Inserted),
+		attribute.Int("batch.skipped", len(result.Skipped)),
+		attribute.Int("batch.failed", len(result.Failed)),
+	)
+	return result, nil
+}
+
+func blockedLinks(ctx context.Context, tx *sql.Tx, cars []models.Car) (map[string]bool, error) {
+	links := make([]string, len(cars))
+	for i, car := range cars {
+		links[i] = car.Link
 	}
 
-	return insertedCount, nil
+	rows, err := tx.QueryContext(ctx, "SELECT link FROM blocked_links WHERE link = ANY($1)", pq.Array(links))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocked := make(map[string]bool)
+	for rows.Next() {
+		var link string
+		if err := rows.Scan(&link); err != nil {
+			return nil, err
+		}
+		blocked[link] = true
+	}
+	return blocked, rows.Err()
 }
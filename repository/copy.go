@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// mergeStaged inserts the first row for each staged link that is neither
+// blocked nor already stored, and lists every staged row that was not
+// inserted with the reason.
+const mergeStaged = `
+	WITH candidates AS (
+		SELECT DISTINCT ON (s.link) s.*
+		FROM cars_staging s
+		WHERE NOT EXISTS (SELECT 1 FROM blocked_links b WHERE b.link = s.link)
+		ORDER BY s.link, s.idx
+	), inserted AS (
+		INSERT INTO cars (title, price, currency, year, location, mileage, link, seller)
+		SELECT title, price, currency, year, location, mileage, link, seller
+		FROM candidates
+		ORDER BY idx
+		ON CONFLICT (link) DO NOTHING
+		RETURNING link
+	)
+	SELECT s.idx, s.link,
+		CASE WHEN c.idx IS NULL THEN 'blocked_link' ELSE 'duplicate_link' END
+	FROM cars_staging s
+	LEFT JOIN candidates c ON c.link = s.link
+	LEFT JOIN inserted i ON i.link = s.link AND c.idx = s.idx
+	WHERE i.link IS NULL
+	ORDER BY s.idx
+`
+
+// CopyInsert bulk-loads cars by streaming them through COPY into a temporary
+// staging table and merging that into cars in one statement. It skips stored
+// and blocked links like InsertBatch, but a row the database rejects fails
+// the whole call, so callers wanting per-row failures should fall back to
+// InsertBatch.
+func (r *carRepository) CopyInsert(ctx context.Context, cars []models.Car) (result models.InsertResult, err error) {
+	if len(cars) == 0 {
+		return result, nil
+	}
+
+	ctx, span := startSpan(ctx, "CarRepository.CopyInsert", "COPY", attribute.Int("batch.size", len(cars)))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Batch)
+	defer cancel()
+
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		result = models.InsertResult{}
+
+		if _, err := tx.ExecContext(ctx, `
+			DROP TABLE IF EXISTS pg_temp.cars_staging;
+			CREATE TEMP TABLE cars_staging (
+				idx INTEGER NOT NULL,
+				title TEXT, price TEXT, currency TEXT, year TEXT,
+				location TEXT, mileage TEXT, link TEXT, seller TEXT
+			) ON COMMIT DROP
+		`); err != nil {
+			return err
+		}
+
+		if err := copyStaged(ctx, tx, cars); err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, mergeStaged)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var row models.RowResult
+			if err := rows.Scan(&row.Index, &row.Link, &row.Reason); err != nil {
+				return err
+			}
+			result.Skipped = append(result.Skipped, row)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		result.Inserted = len(cars) - len(result.Skipped)
+		return nil
+	})
+	if err != nil {
+		return models.InsertResult{}, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("batch.inserted", result.Inserted),
+		attribute.Int("batch.skipped", len(result.Skipped)),
+	)
+	return result, nil
+}
+
+func copyStaged(ctx context.Context, tx *sql.Tx, cars []models.Car) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("cars_staging",
+		"idx", "title", "price", "currency", "year", "location", "mileage", "link", "seller"))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, car := range cars {
+		if _, err := stmt.ExecContext(ctx, i, car.Title, car.Price, car.Currency, car.Year, car.Location, car.Mileage, car.Link, car.Seller); err != nil {
+			return err
+		}
+	}
+	// An Exec without arguments flushes the buffered rows.
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// cursorFetchSize is how many rows each FETCH pulls from the server.
+const cursorFetchSize = 1000
+
+// Each calls fn for every car matching filter, newest first whatever its
+// Sort, and without deal scores. Rows are read through a server-side cursor
+// a page at a time, so memory use does not grow with the result. The cursor
+// lives in the repository's transaction, or in a read-only one of its own.
+// Each FETCH gets the read timeout; the whole scan is only bounded by ctx.
+// Iteration stops at the first error fn returns, which is returned as is
+// rather than mapped to a database error.
+func (r *carRepository) Each(ctx context.Context, filter models.CarFilter, fn func(car *models.Car) error) (err error) {
+	ctx, span := startSpan(ctx, "CarRepository.Each", "SELECT", attribute.String("filter.deleted", string(filter.Deleted)))
+	var fnErr error
+	defer func() {
+		if fnErr != nil {
+			tracing.End(span, fnErr)
+			return
+		}
+		endSpan(span, &err)
+	}()
+	visit := func(car *models.Car) error {
+		if err := fn(car); err != nil {
+			fnErr = err
+			return err
+		}
+		return nil
+	}
+
+	where, args := whereClause(filter)
+	declare := "DECLARE cars_cursor NO SCROLL CURSOR FOR SELECT " + carColumns + " FROM cars" + where + " ORDER BY created_at DESC, id DESC"
+	scan := func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, declare, args...); err != nil {
+			return err
+		}
+		// A shared transaction outlives the scan, so free the cursor name for
+		// the next one.
+		defer tx.ExecContext(ctx, "CLOSE cars_cursor")
+
+		total := 0
+		defer func() { span.SetAttributes(attribute.Int("db.rows", total)) }()
+		for {
+			n, err := r.fetch(ctx, tx, visit)
+			total += n
+			if err != nil {
+				return err
+			}
+			if n < cursorFetchSize {
+				return nil
+			}
+		}
+	}
+
+	if r.tx != nil {
+		return scan(r.tx)
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := scan(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *carRepository) fetch(ctx context.Context, tx *sql.Tx, fn func(car *models.Car) error) (int, error) {
+	fetchCtx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	rows, err := tx.QueryContext(fetchCtx, "FETCH FORWARD "+strconv.Itoa(cursorFetchSize)+" FROM cars_cursor")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var car models.Car
+		if err := scanCar(rows, &car); err != nil {
+			return n, err
+		}
+		n++
+		if err := fn(&car); err != nil {
+			return n, err
+		}
+	}
+	return n, rows.Err()
+}
@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/repository"
+)
+
+func TestEachInTransaction(t *testing.T) {
+	repo := testRepository(t)
+	rollbackTx(t, repo, func(tx repository.CarRepository) error {
+		cars := createCars(t, tx, 3, "")
+
+		// The cars are not committed, so only a scan in tx sees them. Scanning
+		// twice checks the first scan closed its cursor.
+		for scan := 1; scan <= 2; scan++ {
+			seen := make(map[int]bool)
+			err := tx.Each(context.Background(), models.CarFilter{}, func(car *models.Car) error {
+				seen[car.ID] = true
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, car := range cars {
+				if !seen[car.ID] {
+					t.Errorf("scan %d: car %d created in the transaction not seen", scan, car.ID)
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,55 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/migrations"
+	"github.com/yourusername/car-listing-service/repository"
+	_ "github.com/lib/pq"
+)
+
+// testRepository connects to the Postgres database named by
+// TEST_DATABASE_URL and migrates it, skipping when the variable is unset.
+// Callers run their work in rollbackTx so nothing is kept.
+func testRepository(tb testing.TB) repository.CarRepository {
+	tb.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		tb.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		tb.Fatal(err)
+	}
+	return repository.NewCarRepository(db, repository.Timeouts{})
+}
+
+var errRollback = errors.New("rollback")
+
+// rollbackTx runs fn in a transaction that is always rolled back.
+func rollbackTx(tb testing.TB, repo repository.CarRepository, fn func(tx repository.CarRepository) error) {
+	tb.Helper()
+	err := repo.WithTx(context.Background(), func(tx repository.CarRepository) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		tb.Fatal(err)
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+const (
+	// dealYearWindow is how many model years either side still count as
+	// comparable.
+	dealYearWindow = 2
+	// dealMinComparables is the fewest comparables a listing is scored
+	// against.
+	dealMinComparables = 3
+)
+
+// scoreDeals prices every live listing at the median of its comparables:
+// same make, model and currency, within dealYearWindow model years, and a
+// mileage within half its own or 20,000 km. Listings or comparables without
+// a mileage match on the rest. Confirmed scams are scored but never used as
+// comparables. Comparables are looked up per listing with a range scan of
+// cars_make_model_year_idx, so the work grows with the size of each make
+// and model rather than with the square of all listings.
+const scoreDeals = `
+	INSERT INTO car_deal_scores (car_id, fair_price, comparables, deal_score, deal_percentile, scored_at)
+	SELECT s.id, c.fair_price, c.n,
+		(c.fair_price - s.price_amount::float8) / c.fair_price,
+		c.higher::float8 / c.n,
+		CURRENT_TIMESTAMP
+	FROM cars s
+	CROSS JOIN LATERAL (
+		SELECT percentile_cont(0.5) WITHIN GROUP (ORDER BY o.price_amount::float8) AS fair_price,
+			count(*) AS n,
+			count(*) FILTER (WHERE o.price_amount > s.price_amount) AS higher
+		FROM cars o
+		WHERE o.make = s.make AND o.model = s.model
+			AND o.year_num BETWEEN s.year_num - $1 AND s.year_num + $1
+			AND o.deleted_at IS NULL AND o.price_amount > 0 AND o.id <> s.id
+			AND o.currency IS NOT DISTINCT FROM s.currency
+			AND (s.mileage_km IS NULL OR o.mileage_km IS NULL
+				OR abs(o.mileage_km - s.mileage_km) <= greatest(20000, s.mileage_km / 2))
+			AND NOT EXISTS (SELECT 1 FROM fraud_reviews fr WHERE fr.car_id = o.id AND fr.status = 'confirmed')
+	) c
+	WHERE s.deleted_at IS NULL AND s.price_amount > 0
+		AND s.make IS NOT NULL AND s.model IS NOT NULL AND s.year_num IS NOT NULL
+		AND c.n >= $2
+`
+
+// ScoreDeals replaces every deal score in one transaction, so readers see
+// either the old scores or the new ones.
+func (r *carRepository) ScoreDeals(ctx context.Context) (scored int, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.ScoreDeals", "INSERT")
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Batch)
+	defer cancel()
+
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM car_deal_scores"); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, scoreDeals, dealYearWindow, dealMinComparables)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		scored = int(n)
+		return err
+	})
+	return scored, err
+}
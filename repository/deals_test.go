@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+// valueRow scans fixed values, one per destination, like a fetched row.
+type valueRow []interface{}
+
+func (row valueRow) Scan(dest ...interface{}) error {
+	for i := range dest {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(row[i]))
+	}
+	return nil
+}
+
+func TestScanScoredCar(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	scored := time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC)
+	car := []interface{}{
+		42, "2018 Toyota Vios", "₱450,000", "PHP", "2018", "Makati", "60K km",
+		"https://www.facebook.com/marketplace/item/42/", "", 3, created, created, (*time.Time)(nil),
+	}
+
+	var unscored models.Car
+	row := append(append(valueRow{}, car...),
+		sql.NullFloat64{}, sql.NullInt64{}, sql.NullFloat64{}, sql.NullFloat64{}, sql.NullTime{})
+	if err := scanScoredCar(row, &unscored); err != nil {
+		t.Fatal(err)
+	}
+	if unscored.ID != 42 || unscored.Deal != nil {
+		t.Errorf("unscored car = %+v, want ID 42 without a deal", unscored)
+	}
+
+	var dealt models.Car
+	row = append(append(valueRow{}, car...),
+		sql.NullFloat64{Float64: 500000, Valid: true}, sql.NullInt64{Int64: 6, Valid: true},
+		sql.NullFloat64{Float64: 0.1, Valid: true}, sql.NullFloat64{Float64: 0.8, Valid: true},
+		sql.NullTime{Time: scored, Valid: true})
+	if err := scanScoredCar(row, &dealt); err != nil {
+		t.Fatal(err)
+	}
+	want := models.DealScore{FairPrice: 500000, Comparables: 6, Score: 0.1, Percentile: 0.8, ScoredAt: scored}
+	if dealt.Deal == nil || *dealt.Deal != want {
+		t.Errorf("deal = %+v, want %+v", dealt.Deal, want)
+	}
+}
+
+func TestOrderClause(t *testing.T) {
+	for sort, want := range map[models.CarSort]string{
+		"":                        " ORDER BY created_at DESC",
+		models.SortNewest:         " ORDER BY created_at DESC",
+		models.SortDealScore:      " ORDER BY deal_score DESC NULLS LAST, created_at DESC",
+		models.SortDealPercentile: " ORDER BY deal_percentile DESC NULLS LAST, deal_score DESC NULLS LAST, created_at DESC",
+	} {
+		if got := orderClause(models.CarFilter{Sort: sort}); got != want {
+			t.Errorf("sort %q: %q, want %q", sort, got, want)
+		}
+	}
+}
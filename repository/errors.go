@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/lib/pq"
+)
+
+const uniqueViolation = "23505"
+
+// mapError translates driver errors into domain errors. notFound is returned
+// for sql.ErrNoRows so each call can name what was missing.
+func mapError(err error, notFound *apperror.Error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) && notFound != nil {
+		return notFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == uniqueViolation && pqErr.Constraint == "cars_link_key":
+			return apperror.Conflict("duplicate_link", "A car with this link already exists", err)
+		case pqErr.Code == uniqueViolation:
+			return apperror.Conflict("duplicate", "The record conflicts with an existing one", err)
+		case pqErr.Code.Class() == "08", pqErr.Code.Class() == "53", pqErr.Code.Class() == "57":
+			// connection exceptions, insufficient resources, operator intervention
+			return apperror.Unavailable("database_unavailable", "The database is temporarily unavailable", err)
+		}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr) {
+		return apperror.Unavailable("database_unavailable", "The database is temporarily unavailable", err)
+	}
+
+	return err
+}
+
+// fatal reports whether err leaves nothing worth retrying row by row: the
+// context is done or the database is unreachable.
+func fatal(ctx context.Context, err error) bool {
+	return ctx.Err() != nil || apperror.KindOf(mapError(err, nil)) == apperror.KindUnavailable
+}
+
+// rowFailure names why the database rejected a row, using the Postgres
+// condition name when there is one.
+func rowFailure(err error) (reason, message string) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Name(), pqErr.Message
+	}
+	return "error", err.Error()
+}
+
+func carNotFound() *apperror.Error {
+	return apperror.NotFound("car_not_found", "Car not found")
+}
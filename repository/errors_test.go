@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/lib/pq"
+)
+
+func TestMapError(t *testing.T) {
+	other := errors.New("syntax error")
+	tests := []struct {
+		name string
+		err  error
+		kind apperror.Kind
+		code string
+	}{
+		{name: "no rows", err: sql.ErrNoRows, kind: apperror.KindNotFound, code: "car_not_found"},
+		{name: "duplicate link", err: &pq.Error{Code: uniqueViolation, Constraint: "cars_link_key"}, kind: apperror.KindConflict, code: "duplicate_link"},
+		{name: "other unique", err: &pq.Error{Code: uniqueViolation, Constraint: "blocked_links_pkey"}, kind: apperror.KindConflict, code: "duplicate"},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, kind: apperror.KindUnavailable, code: "database_unavailable"},
+		{name: "admin shutdown", err: &pq.Error{Code: "57P01"}, kind: apperror.KindUnavailable, code: "database_unavailable"},
+		{name: "unknown", err: other, kind: apperror.KindInternal, code: "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mapError(tt.err, carNotFound())
+			if got := apperror.From(err); got.Kind != tt.kind || got.Code != tt.code {
+				t.Errorf("mapped to %v %q, want %v %q", got.Kind, got.Code, tt.kind, tt.code)
+			}
+		})
+	}
+	if err := mapError(other, nil); err != other {
+		t.Errorf("unknown errors are returned as is, got %v", err)
+	}
+	if err := mapError(sql.ErrNoRows, nil); err != sql.ErrNoRows {
+		t.Errorf("no rows without a not-found error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestRowFailure(t *testing.T) {
+	reason, message := rowFailure(&pq.Error{Code: "23514", Message: `new row violates check constraint "cars_title_check"`})
+	if reason != "check_violation" || message != `new row violates check constraint "cars_title_check"` {
+		t.Errorf("rowFailure = %q, %q", reason, message)
+	}
+	if reason, message := rowFailure(errors.New("boom")); reason != "error" || message != "boom" {
+		t.Errorf("rowFailure of a foreign error = %q, %q", reason, message)
+	}
+}
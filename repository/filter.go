@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+// confirmedScam matches cars a moderator confirmed as scams.
+const confirmedScam = "EXISTS (SELECT 1 FROM fraud_reviews fr WHERE fr.car_id = cars.id AND fr.status = 'confirmed')"
+
+// whereClause renders filter as a WHERE clause and its arguments.
+func whereClause(filter models.CarFilter) (string, []interface{}) {
+	var conditions []string
+	switch filter.Deleted {
+	case models.DeletedInclude:
+	case models.DeletedOnly:
+		conditions = append(conditions, "deleted_at IS NOT NULL")
+	default:
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	switch filter.Scams {
+	case models.ScamsInclude:
+	case models.ScamsOnly:
+		conditions = append(conditions, confirmedScam)
+	default:
+		conditions = append(conditions, "NOT "+confirmedScam)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), nil
+}
+
+// orderClause renders the filter's sort as an ORDER BY clause over cars
+// joined to their deal scores. Unscored cars sort after scored ones.
+func orderClause(filter models.CarFilter) string {
+	switch filter.Sort {
+	case models.SortDealScore:
+		return " ORDER BY deal_score DESC NULLS LAST, created_at DESC"
+	case models.SortDealPercentile:
+		return " ORDER BY deal_percentile DESC NULLS LAST, deal_score DESC NULLS LAST, created_at DESC"
+	default:
+		return " ORDER BY created_at DESC"
+	}
+}
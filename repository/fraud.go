@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// extraScanner appends destinations after the ones a scan helper passes, so
+// queries can select columns beyond carColumns and reuse scanScoredCar.
+type extraScanner struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// FraudCandidates returns every live listing with its deal score, the
+// number of other live listings with the same title, ignoring case and
+// spacing, and the number of live listings its seller created since
+// sellerSince. Listings without a known seller count none.
+func (r *carRepository) FraudCandidates(ctx context.Context, sellerSince time.Time) (candidates []models.FraudCandidate, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.FraudCandidates", "SELECT")
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Batch)
+	defer cancel()
+
+	rows, err := r.conn().QueryContext(ctx, "SELECT "+carColumns+", "+dealColumns+
+		", count(*) OVER (PARTITION BY lower(regexp_replace(btrim(title), '\\s+', ' ', 'g'))) - 1"+
+		", count(*) FILTER (WHERE seller <> '' AND created_at >= $1) OVER (PARTITION BY seller)"+
+		scoredCarsFrom+" WHERE deleted_at IS NULL", sellerSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var candidate models.FraudCandidate
+		if err := scanScoredCar(extraScanner{rows, []interface{}{&candidate.TitleReuse, &candidate.SellerRecentPosts}}, &candidate.Car); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	span.SetAttributes(attribute.Int("db.rows", len(candidates)))
+	return candidates, rows.Err()
+}
+
+// ReplaceFraudFlags swaps every stored flag for flags in one transaction.
+// A flag raised again keeps the time it was first detected.
+func (r *carRepository) ReplaceFraudFlags(ctx context.Context, flags []models.FraudFlag) (err error) {
+	ctx, span := startSpan(ctx, "CarRepository.ReplaceFraudFlags", "INSERT", attribute.Int("batch.size", len(flags)))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Batch)
+	defer cancel()
+
+	carIDs := make([]int64, len(flags))
+	rules := make([]string, len(flags))
+	weights := make([]float64, len(flags))
+	reasons := make([]string, len(flags))
+	for i, flag := range flags {
+		carIDs[i], rules[i], weights[i], reasons[i] = int64(flag.CarID), flag.Rule, flag.Weight, flag.Reason
+	}
+
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			DELETE FROM fraud_flags
+			WHERE (car_id, rule) NOT IN (SELECT * FROM unnest($1::integer[], $2::text[]))`,
+			pq.Array(carIDs), pq.Array(rules))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO fraud_flags (car_id, rule, weight, reason)
+			SELECT * FROM unnest($1::integer[], $2::text[], $3::float8[], $4::text[])
+			ON CONFLICT (car_id, rule) DO UPDATE
+				SET weight = EXCLUDED.weight, reason = EXCLUDED.reason`,
+			pq.Array(carIDs), pq.Array(rules), pq.Array(weights), pq.Array(reasons))
+		return err
+	})
+}
+
+// FraudCases lists flagged live listings in the query's review state with a
+// score of at least MinScore, highest score first.
+func (r *carRepository) FraudCases(ctx context.Context, query models.FraudCaseQuery) (cases []models.FraudCase, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.FraudCases", "SELECT", attribute.String("fraud.status", string(query.Status)))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	status := "fr.status IS NULL"
+	args := []interface{}{query.MinScore, query.Limit}
+	if query.Status != models.FraudPending {
+		args = append(args, string(query.Status))
+		status = "fr.status = $3"
+	}
+
+	rows, err := r.conn().QueryContext(ctx, `
+		SELECT `+carColumns+`, `+dealColumns+`, flagged.score, flagged.flags, fr.status, fr.note, fr.reviewed_at
+		FROM (
+			SELECT car_id AS flagged_id, sum(weight) AS score,
+				json_agg(json_build_object('rule', rule, 'weight', weight, 'reason', reason, 'detected_at', detected_at)
+					ORDER BY weight DESC, rule) AS flags
+			FROM fraud_flags
+			GROUP BY car_id
+		) flagged
+		JOIN cars ON cars.id = flagged.flagged_id
+		LEFT JOIN car_deal_scores ON car_deal_scores.car_id = cars.id
+		LEFT JOIN fraud_reviews fr ON fr.car_id = cars.id
+		WHERE deleted_at IS NULL AND flagged.score >= $1 AND `+status+`
+		ORDER BY flagged.score DESC, cars.id
+		LIMIT $2`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.FraudCase
+		var flags []byte
+		var reviewStatus, reviewNote sql.NullString
+		var reviewedAt sql.NullTime
+		scanner := extraScanner{rows, []interface{}{&c.Score, &flags, &reviewStatus, &reviewNote, &reviewedAt}}
+		if err := scanScoredCar(scanner, &c.Car); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(flags, &c.Flags); err != nil {
+			return nil, fmt.Errorf("decode fraud flags of car %d: %w", c.Car.ID, err)
+		}
+		for i := range c.Flags {
+			c.Flags[i].CarID = c.Car.ID
+		}
+		if reviewStatus.Valid {
+			c.Review = &models.FraudReview{
+				Status:     models.FraudReviewStatus(reviewStatus.String),
+				Note:       reviewNote.String,
+				ReviewedAt: reviewedAt.Time,
+			}
+		}
+		cases = append(cases, c)
+	}
+
+	span.SetAttributes(attribute.Int("db.rows", len(cases)))
+	return cases, rows.Err()
+}
+
+// ReviewFraud records a moderator's verdict on a live listing, replacing
+// any earlier one.
+func (r *carRepository) ReviewFraud(ctx context.Context, carID int, status models.FraudReviewStatus, note string) (review *models.FraudReview, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.ReviewFraud", "INSERT", attribute.Int("car.id", carID))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	review = &models.FraudReview{Status: status, Note: note}
+	err = r.conn().QueryRowContext(ctx, `
+		INSERT INTO fraud_reviews (car_id, status, note)
+		SELECT id, $2, $3 FROM cars WHERE id = $1 AND deleted_at IS NULL
+		ON CONFLICT (car_id) DO UPDATE
+			SET status = EXCLUDED.status, note = EXCLUDED.note, reviewed_at = CURRENT_TIMESTAMP
+		RETURNING reviewed_at`, carID, string(status), note,
+	).Scan(&review.ReviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
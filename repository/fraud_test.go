@@ -0,0 +1,123 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/repository"
+)
+
+// createCars stores n listings with links no other test run uses.
+func createCars(t *testing.T, repo repository.CarRepository, n int, seller string) []models.Car {
+	t.Helper()
+	base := time.Now().UnixNano()
+	cars := make([]models.Car, n)
+	for i := range cars {
+		cars[i] = models.Car{
+			Title:   "2017 Toyota Vios 1.3 E",
+			Price:   "₱420,000",
+			Mileage: "60K km",
+			Link:    fmt.Sprintf("https://www.facebook.com/marketplace/item/%d/", base+int64(i)),
+			Seller:  seller,
+		}
+		if err := repo.Create(context.Background(), &cars[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cars
+}
+
+func listedIDs(t *testing.T, repo repository.CarRepository, scams models.ScamFilter) map[int]bool {
+	t.Helper()
+	cars, err := repo.GetAll(context.Background(), models.CarFilter{Scams: scams})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[int]bool, len(cars))
+	for _, car := range cars {
+		ids[car.ID] = true
+	}
+	return ids
+}
+
+func TestConfirmedScamsAreHidden(t *testing.T) {
+	repo := testRepository(t)
+	rollbackTx(t, repo, func(tx repository.CarRepository) error {
+		cars := createCars(t, tx, 3, "")
+		scam, dismissed, clean := cars[0].ID, cars[1].ID, cars[2].ID
+		if _, err := tx.ReviewFraud(context.Background(), scam, models.FraudConfirmed, "deposit scam"); err != nil {
+			return err
+		}
+		if _, err := tx.ReviewFraud(context.Background(), dismissed, models.FraudDismissed, ""); err != nil {
+			return err
+		}
+
+		tests := []struct {
+			scams models.ScamFilter
+			want  map[int]bool
+		}{
+			{"", map[int]bool{scam: false, dismissed: true, clean: true}},
+			{models.ScamsExclude, map[int]bool{scam: false, dismissed: true, clean: true}},
+			{models.ScamsInclude, map[int]bool{scam: true, dismissed: true, clean: true}},
+			{models.ScamsOnly, map[int]bool{scam: true, dismissed: false, clean: false}},
+		}
+		for _, tt := range tests {
+			listed := listedIDs(t, tx, tt.scams)
+			for id, want := range tt.want {
+				if listed[id] != want {
+					t.Errorf("scams=%q: car %d listed = %v, want %v", tt.scams, id, listed[id], want)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func TestFraudCandidatesCountSellerPosts(t *testing.T) {
+	repo := testRepository(t)
+	rollbackTx(t, repo, func(tx repository.CarRepository) error {
+		seller := fmt.Sprint(time.Now().UnixNano())
+		active := createCars(t, tx, 5, seller)
+		unknown := createCars(t, tx, 1, "")
+		if err := tx.Delete(context.Background(), active[4].ID); err != nil {
+			return err
+		}
+
+		candidates, err := tx.FraudCandidates(context.Background(), time.Now().Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+		posts := make(map[int]int, len(candidates))
+		for _, candidate := range candidates {
+			posts[candidate.Car.ID] = candidate.SellerRecentPosts
+		}
+
+		// The deleted listing is neither checked nor counted.
+		for _, car := range active[:4] {
+			if posts[car.ID] != 4 {
+				t.Errorf("car %d: seller posts = %d, want 4", car.ID, posts[car.ID])
+			}
+		}
+		if _, ok := posts[active[4].ID]; ok {
+			t.Errorf("deleted car %d is a candidate", active[4].ID)
+		}
+		if posts[unknown[0].ID] != 0 {
+			t.Errorf("car without seller: seller posts = %d, want 0", posts[unknown[0].ID])
+		}
+
+		// Listings created before sellerSince are not recent.
+		candidates, err = tx.FraudCandidates(context.Background(), time.Now().Add(time.Hour))
+		if err != nil {
+			return err
+		}
+		for _, candidate := range candidates {
+			if candidate.Car.ID == active[0].ID && candidate.SellerRecentPosts != 0 {
+				t.Errorf("seller posts since the future = %d, want 0", candidate.SellerRecentPosts)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/repository"
+	"github.com/yourusername/car-listing-service/testutil/fakemarketplace"
+)
+
+// BenchmarkIngest compares the row-by-row InsertBatch path with the COPY
+// based CopyInsert path. Run it against a scratch database:
+//
+//	TEST_DATABASE_URL=postgres://... go test ./repository -run '^$' -bench Ingest
+func BenchmarkIngest(b *testing.B) {
+	repo := testRepository(b)
+	methods := []struct {
+		name   string
+		insert func(repo repository.CarRepository, ctx context.Context, cars []models.Car) (models.InsertResult, error)
+	}{
+		{"InsertBatch", repository.CarRepository.InsertBatch},
+		{"CopyInsert", repository.CarRepository.CopyInsert},
+	}
+
+	for _, size := range []int{100, 1000, 10000} {
+		for _, m := range methods {
+			b.Run(fmt.Sprintf("%s/%d", m.name, size), func(b *testing.B) {
+				cars := benchCars(size)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					rollbackTx(b, repo, func(tx repository.CarRepository) error {
+						_, err := m.insert(tx, context.Background(), cars)
+						return err
+					})
+				}
+				b.ReportMetric(float64(size*b.N)/b.Elapsed().Seconds(), "rows/s")
+			})
+		}
+	}
+}
+
+// benchCars builds n listings shaped like the fake Marketplace feed, every
+// tenth repeating the previous link.
+func benchCars(n int) []models.Car {
+	feed := fakemarketplace.NewUnstarted(fakemarketplace.Options{Listings: n})
+	cars := make([]models.Car, n)
+	for i := range cars {
+		id := i
+		if i > 0 && i%10 == 0 {
+			id = i - 1
+		}
+		listing := feed.Listing(id)
+		cars[i] = models.Car{
+			Title:    listing.Title,
+			Price:    listing.Price,
+			Currency: "PHP",
+			Mileage:  listing.Mileage,
+			Location: listing.Location,
+			Link:     fmt.Sprintf("https://www.facebook.com/marketplace/item/9%08d/", id),
+		}
+	}
+	return cars
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// statsColumns maps each grouping dimension to its column.
+var statsColumns = map[models.StatsDimension]string{
+	models.StatsByMake:     "make",
+	models.StatsByModel:    "model",
+	models.StatsByYear:     "year_num",
+	models.StatsByLocation: "location",
+}
+
+// mileageEdges are the lower bounds of the mileage histogram buckets in km.
+var mileageEdges = []float64{0, 25000, 50000, 75000, 100000, 150000, 200000, 300000}
+
+// statsPercentiles are the percentiles scanned into Distribution, in order.
+const statsPercentiles = "ARRAY[0.1, 0.25, 0.5, 0.75, 0.9]"
+
+// distributionColumns aggregates column into the values distributionScan
+// reads.
+func distributionColumns(column string) string {
+	return fmt.Sprintf("count(%[1]s), min(%[1]s)::float8, avg(%[1]s)::float8, "+
+		"percentile_cont(%[2]s) WITHIN GROUP (ORDER BY %[1]s::float8), max(%[1]s)::float8", column, statsPercentiles)
+}
+
+// Stats aggregates live listings in the database, one row per group, with
+// the largest groups first.
+func (r *carRepository) Stats(ctx context.Context, query models.StatsQuery) (groups []models.StatsGroup, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.Stats", "SELECT", attribute.Int("stats.dimensions", len(query.GroupBy)))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var selects, groupBy []string
+	for i, dim := range query.GroupBy {
+		selects = append(selects, statsColumns[dim])
+		groupBy = append(groupBy, fmt.Sprint(i+1))
+	}
+	selects = append(selects, "count(*)", distributionColumns("price_amount"), distributionColumns("mileage_km"))
+	for i, edge := range mileageEdges {
+		if i+1 < len(mileageEdges) {
+			selects = append(selects, fmt.Sprintf("count(*) FILTER (WHERE mileage_km >= %g AND mileage_km < %g)", edge, mileageEdges[i+1]))
+		} else {
+			selects = append(selects, fmt.Sprintf("count(*) FILTER (WHERE mileage_km >= %g)", edge))
+		}
+	}
+
+	where, args := statsWhere(query)
+	sqlQuery := "SELECT " + strings.Join(selects, ", ") + " FROM cars" + where
+	if len(groupBy) > 0 {
+		sqlQuery += " GROUP BY " + strings.Join(groupBy, ", ") + " ORDER BY count(*) DESC, " + strings.Join(groupBy, ", ")
+	}
+	args = append(args, query.Limit)
+	sqlQuery += fmt.Sprintf(" LIMIT $%d", len(args))
+
+	rows, err := r.conn().QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		group, err := scanStatsGroup(rows, query.GroupBy)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	span.SetAttributes(attribute.Int("db.rows", len(groups)))
+	return groups, rows.Err()
+}
+
+// statsWhere renders the query's filters as a WHERE clause over live
+// listings that are not confirmed scams.
+func statsWhere(query models.StatsQuery) (string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL", "NOT " + confirmedScam}
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.Make != "" {
+		add("make = lower($%d)", query.Make)
+	}
+	if query.Model != "" {
+		add("model = lower($%d)", query.Model)
+	}
+	if query.Location != "" {
+		add("strpos(lower(location), lower($%d)) > 0", query.Location)
+	}
+	if query.Currency != "" {
+		add("currency = $%d", query.Currency)
+	}
+	if query.YearMin > 0 {
+		add("year_num >= $%d", query.YearMin)
+	}
+	if query.YearMax > 0 {
+		add("year_num <= $%d", query.YearMax)
+	}
+	if query.Since != nil {
+		add("created_at >= $%d", *query.Since)
+	}
+	if query.Until != nil {
+		add("created_at < $%d", *query.Until)
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanStatsGroup(row rowScanner, dims []models.StatsDimension) (models.StatsGroup, error) {
+	var group models.StatsGroup
+	keys := make([]sql.NullString, len(dims))
+	var price, mileage distributionScan
+	histogram := make([]int, len(mileageEdges))
+
+	dest := make([]interface{}, 0, len(dims)+1+len(histogram)+10)
+	for i := range keys {
+		dest = append(dest, &keys[i])
+	}
+	dest = append(dest, &group.Count)
+	dest = append(dest, price.dest()...)
+	dest = append(dest, mileage.dest()...)
+	for i := range histogram {
+		dest = append(dest, &histogram[i])
+	}
+	if err := row.Scan(dest...); err != nil {
+		return group, err
+	}
+
+	for i, dim := range dims {
+		switch dim {
+		case models.StatsByMake:
+			group.Make = keys[i].String
+		case models.StatsByModel:
+			group.Model = keys[i].String
+		case models.StatsByYear:
+			group.Year, _ = strconv.Atoi(keys[i].String)
+		case models.StatsByLocation:
+			group.Location = keys[i].String
+		}
+	}
+	group.Price = price.distribution()
+	group.Mileage.Distribution = mileage.distribution()
+	group.Mileage.Histogram = make([]models.HistogramBucket, len(mileageEdges))
+	for i, edge := range mileageEdges {
+		bucket := models.HistogramBucket{Min: edge, Count: histogram[i]}
+		if i+1 < len(mileageEdges) {
+			upper := mileageEdges[i+1]
+			bucket.Max = &upper
+		}
+		group.Mileage.Histogram[i] = bucket
+	}
+	return group, nil
+}
+
+// distributionScan receives the columns of distributionColumns.
+type distributionScan struct {
+	count          int
+	min, mean, max sql.NullFloat64
+	percentiles    pq.Float64Array
+}
+
+func (d *distributionScan) dest() []interface{} {
+	return []interface{}{&d.count, &d.min, &d.mean, &d.percentiles, &d.max}
+}
+
+func (d *distributionScan) distribution() models.Distribution {
+	value := func(v sql.NullFloat64) *float64 {
+		if !v.Valid {
+			return nil
+		}
+		return &v.Float64
+	}
+	percentile := func(i int) *float64 {
+		if i >= len(d.percentiles) {
+			return nil
+		}
+		return &d.percentiles[i]
+	}
+	return models.Distribution{
+		Count:  d.count,
+		Min:    value(d.min),
+		Mean:   value(d.mean),
+		P10:    percentile(0),
+		P25:    percentile(1),
+		Median: percentile(2),
+		P75:    percentile(3),
+		P90:    percentile(4),
+		Max:    value(d.max),
+	}
+}
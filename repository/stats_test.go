@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+func TestStatsWhere(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	where, args := statsWhere(models.StatsQuery{
+		Make:     "Toyota",
+		Location: "makati",
+		Currency: "PHP",
+		YearMin:  2015,
+		Since:    &since,
+	})
+
+	want := " WHERE deleted_at IS NULL AND NOT " + confirmedScam +
+		" AND make = lower($1) AND strpos(lower(location), lower($2)) > 0 AND currency = $3 AND year_num >= $4 AND created_at >= $5"
+	if where != want {
+		t.Errorf("where =\n%s\nwant\n%s", where, want)
+	}
+	wantArgs := []interface{}{"Toyota", "makati", "PHP", 2015, since}
+	if len(args) != len(wantArgs) {
+		t.Fatalf("args = %v, want %v", args, wantArgs)
+	}
+	for i := range args {
+		if args[i] != wantArgs[i] {
+			t.Errorf("arg $%d = %v, want %v", i+1, args[i], wantArgs[i])
+		}
+	}
+
+	// Without filters only live listings that are not confirmed scams count.
+	if where, args := statsWhere(models.StatsQuery{}); where != " WHERE deleted_at IS NULL AND NOT "+confirmedScam || len(args) != 0 {
+		t.Errorf("unfiltered where = %q with args %v", where, args)
+	}
+}
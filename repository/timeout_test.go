@@ -0,0 +1,86 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/repository"
+)
+
+// stallDriver opens connections whose queries block until their context is
+// done, like a database stuck behind a lock.
+type stallDriver struct{}
+
+func (stallDriver) Open(string) (driver.Conn, error) { return stallConn{}, nil }
+
+type stallConn struct{}
+
+func (stallConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (stallConn) Close() error                        { return nil }
+func (stallConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (stallConn) QueryContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Rows, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (stallConn) ExecContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func init() {
+	sql.Register("stall", stallDriver{})
+}
+
+func TestQueryTimeouts(t *testing.T) {
+	db, err := sql.Open("stall", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	read := func(repo repository.CarRepository, ctx context.Context) error {
+		_, err := repo.GetByID(ctx, 1)
+		return err
+	}
+	write := func(repo repository.CarRepository, ctx context.Context) error {
+		return repo.Create(ctx, &models.Car{Title: "2018 Toyota Vios", Link: "https://example.com/1"})
+	}
+
+	tests := []struct {
+		name     string
+		timeouts repository.Timeouts
+		deadline time.Duration
+		call     func(repository.CarRepository, context.Context) error
+	}{
+		{name: "read timeout", timeouts: repository.Timeouts{Read: 20 * time.Millisecond, Write: time.Hour}, call: read},
+		{name: "write timeout", timeouts: repository.Timeouts{Read: time.Hour, Write: 20 * time.Millisecond}, call: write},
+		{name: "caller deadline", deadline: 20 * time.Millisecond, call: read},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			start := time.Now()
+			err := tt.call(repository.NewCarRepository(db, tt.timeouts), ctx)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("call returned after %v", elapsed)
+			}
+			if apperror.KindOf(err) != apperror.KindUnavailable || !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("err = %v, want a database_unavailable error wrapping the deadline", err)
+			}
+		})
+	}
+}
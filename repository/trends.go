@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/car-listing-service/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PriceTrends charts the median price and volume per period from the
+// price_trend_points view, as of its last refresh.
+func (r *carRepository) PriceTrends(ctx context.Context, query models.TrendQuery) (points []models.TrendPoint, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.PriceTrends", "SELECT", attribute.String("trend.interval", string(query.Interval)))
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	conditions := []string{"period = $1", "make = lower($2)", "currency = $3"}
+	args := []interface{}{string(query.Interval), query.Make, query.Currency}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if query.Model != "" {
+		add("model = lower($%d)", query.Model)
+	}
+	if query.YearMin > 0 {
+		add("year_num >= $%d", query.YearMin)
+	}
+	if query.YearMax > 0 {
+		add("year_num <= $%d", query.YearMax)
+	}
+	if query.Since != nil {
+		add("period_start >= date_trunc($1, $%d::timestamptz AT TIME ZONE 'UTC')", *query.Since)
+	}
+	if query.Until != nil {
+		add("period_start < $%d::timestamptz AT TIME ZONE 'UTC'", *query.Until)
+	}
+
+	rows, err := r.conn().QueryContext(ctx, `
+		SELECT period_start, count(*), count(*) FILTER (WHERE listed),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY price_amount::float8)
+		FROM price_trend_points
+		WHERE `+strings.Join(conditions, " AND ")+`
+		GROUP BY period_start
+		ORDER BY period_start`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var point models.TrendPoint
+		var periodStart time.Time
+		if err := rows.Scan(&periodStart, &point.Listings, &point.NewListings, &point.MedianPrice); err != nil {
+			return nil, err
+		}
+		// period_start is a UTC wall-clock time without a zone.
+		point.PeriodStart = time.Date(periodStart.Year(), periodStart.Month(), periodStart.Day(), 0, 0, 0, 0, time.UTC)
+		points = append(points, point)
+	}
+
+	span.SetAttributes(attribute.Int("db.rows", len(points)))
+	return points, rows.Err()
+}
+
+// RefreshPriceTrends recomputes the price_trend_points view without blocking
+// readers.
+func (r *carRepository) RefreshPriceTrends(ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "CarRepository.RefreshPriceTrends", "REFRESH")
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Batch)
+	defer cancel()
+
+	_, err = r.conn().ExecContext(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY price_trend_points")
+	return err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// dbtx is the part of *sql.DB and *sql.Tx the repository queries through.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// conn returns the transaction the repository is bound to, or the pool.
+func (r *carRepository) conn() dbtx {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// WithTx runs fn against a repository whose calls share one transaction. The
+// transaction commits if fn returns nil and rolls back otherwise. Nested calls
+// join the outer transaction.
+func (r *carRepository) WithTx(ctx context.Context, fn func(repo CarRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(err, nil)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&carRepository{db: r.db, tx: tx, timeouts: r.timeouts}); err != nil {
+		return err
+	}
+	return mapError(tx.Commit(), nil)
+}
+
+// inTx runs fn in the repository's transaction, or in a new one committed
+// when fn succeeds.
+func (r *carRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
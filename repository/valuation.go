@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ValuationSamples returns the make, model, year, mileage, location and
+// price of every live listing priced in currency, leaving out confirmed
+// scams.
+func (r *carRepository) ValuationSamples(ctx context.Context, currency string) (samples []models.ValuationSample, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.ValuationSamples", "SELECT")
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Batch)
+	defer cancel()
+
+	rows, err := r.conn().QueryContext(ctx, `
+		SELECT make, COALESCE(model, ''), year_num, mileage_km::float8, COALESCE(location, ''), price_amount::float8
+		FROM cars
+		WHERE deleted_at IS NULL AND currency = $1 AND price_amount > 0
+			AND make IS NOT NULL AND year_num IS NOT NULL
+			AND NOT `+confirmedScam, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.ValuationSample
+		if err := rows.Scan(&s.Make, &s.Model, &s.Year, &s.MileageKm, &s.Location, &s.Price); err != nil {
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+
+	span.SetAttributes(attribute.Int("db.rows", len(samples)))
+	return samples, rows.Err()
+}
+
+// SaveValuationModel stores a trained model as the newest version and fills
+// in its version and training time.
+func (r *carRepository) SaveValuationModel(ctx context.Context, model *models.ValuationModelRecord) (err error) {
+	ctx, span := startSpan(ctx, "CarRepository.SaveValuationModel", "INSERT")
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	metrics, err := json.Marshal(model.Metrics)
+	if err != nil {
+		return err
+	}
+	return r.conn().QueryRowContext(ctx, `
+		INSERT INTO valuation_models (currency, training_rows, metrics, params)
+		VALUES ($1, $2, $3, $4)
+		RETURNING version, trained_at`,
+		model.Currency, model.TrainingRows, metrics, []byte(model.Params),
+	).Scan(&model.Version, &model.TrainedAt)
+}
+
+// LatestValuationModel returns the newest model for currency. It skips
+// loading the parameters when the newest is still knownVersion, returning
+// the record without them.
+func (r *carRepository) LatestValuationModel(ctx context.Context, currency string, knownVersion int) (_ *models.ValuationModelRecord, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.LatestValuationModel", "SELECT")
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var model models.ValuationModelRecord
+	var metrics []byte
+	var params []byte
+	err = r.conn().QueryRowContext(ctx, `
+		SELECT version, currency, trained_at, training_rows, metrics,
+			CASE WHEN version = $2 THEN NULL ELSE params END
+		FROM valuation_models
+		WHERE currency = $1
+		ORDER BY version DESC
+		LIMIT 1`, currency, knownVersion,
+	).Scan(&model.Version, &model.Currency, &model.TrainedAt, &model.TrainingRows, &metrics, &params)
+	if err != nil {
+		return nil, mapError(err, valuationModelNotFound())
+	}
+	if err := json.Unmarshal(metrics, &model.Metrics); err != nil {
+		return nil, err
+	}
+	model.Params = params
+	return &model, nil
+}
+
+// ValuationModels lists the stored models for currency, newest first,
+// without their parameters.
+func (r *carRepository) ValuationModels(ctx context.Context, currency string) (infos []models.ValuationModelInfo, err error) {
+	ctx, span := startSpan(ctx, "CarRepository.ValuationModels", "SELECT")
+	defer endSpan(span, &err)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	rows, err := r.conn().QueryContext(ctx, `
+		SELECT version, currency, trained_at, training_rows, metrics
+		FROM valuation_models
+		WHERE currency = $1
+		ORDER BY version DESC`, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var info models.ValuationModelInfo
+		var metrics []byte
+		if err := rows.Scan(&info.Version, &info.Currency, &info.TrainedAt, &info.TrainingRows, &metrics); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(metrics, &info.Metrics); err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, rows.Err()
+}
+
+func valuationModelNotFound() *apperror.Error {
+	return apperror.Unavailable("valuation_model_missing", "No valuation model has been trained yet", nil)
+}
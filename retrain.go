@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yourusername/car-listing-service/config"
+)
+
+func runRetrain(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("retrain", flag.ContinueOnError)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close(context.Background())
+
+	info, err := a.service.TrainValuationModel(ctx)
+	if err != nil {
+		return err
+	}
+
+	m := info.Metrics
+	fmt.Fprintf(os.Stderr, "trained valuation model %d on %d listings (lambda %g)\n", info.Version, info.TrainingRows, m.Lambda)
+	fmt.Fprintf(os.Stderr, "held out %d: MAE %.0f, RMSE %.0f, MAPE %.1f%%, R² %.3f\n", m.TestRows, m.MAE, m.RMSE, m.MAPE*100, m.R2)
+	return nil
+}
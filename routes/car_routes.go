@@ -11,10 +11,22 @@ func SetupRoutes(router *gin.Engine, carController *controllers.CarController) {
 		v1 := api.Group("/v1")
 		{
 			v1.GET("/cars", carController.GetCars)
+			v1.GET("/cars/export", carController.ExportCars)
 			v1.GET("/cars/:id", carController.GetCarByID)
 			v1.POST("/cars", carController.CreateCar)
+			v1.POST("/cars/import", carController.ImportCars)
+			v1.POST("/cars:action", carController.CarsAction)
+			v1.GET("/stats", carController.GetStats)
+			v1.GET("/stats/trends", carController.GetPriceTrends)
+			v1.POST("/valuations", carController.Valuate)
+			v1.GET("/valuations/models", carController.GetValuationModels)
+			v1.GET("/fraud/cases", carController.GetFraudCases)
+			v1.POST("/fraud/cases/:id/confirm", carController.ConfirmFraud)
+			v1.POST("/fraud/cases/:id/dismiss", carController.DismissFraud)
 			v1.PUT("/cars/:id", carController.UpdateCar)
+			v1.PATCH("/cars/:id", carController.PatchCar)
 			v1.DELETE("/cars/:id", carController.DeleteCar)
+			v1.POST("/cars/:id/restore", carController.RestoreCar)
 			v1.POST("/scrape", carController.ScrapeCars)
 		}
 	}
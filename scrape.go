@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/services"
+)
+
+func runScrape(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("scrape", flag.ContinueOnError)
+	flags.StringVar(&cfg.Scraper.TargetURL, "target", cfg.Scraper.TargetURL, "Marketplace search URL to scrape")
+	flags.BoolVar(&cfg.Scraper.Headless, "headless", cfg.Scraper.Headless, "run Chrome headless")
+	flags.StringVar(&cfg.Scraper.RecordDir, "record", cfg.Scraper.RecordDir, "record the session to this directory")
+	flags.StringVar(&cfg.Scraper.ReplayDir, "replay", cfg.Scraper.ReplayDir, "replay a recorded session instead of launching Chrome")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close(context.Background())
+
+	result, err := a.service.ScrapeAndStoreCars(ctx, func(progress services.ScrapeResult) {
+		fmt.Printf("batch %d: found %d, inserted %d, skipped %d, failed %d so far\n",
+			progress.Batches, progress.Found, progress.Inserted, progress.Skipped, progress.Failed)
+	})
+	if result != nil {
+		fmt.Printf("job %s: %d batches, %d listings found, %d inserted, %d skipped, %d failed\n",
+			result.JobID, result.Batches, result.Found, result.Inserted, result.Skipped, result.Failed)
+		for _, row := range result.Failures {
+			fmt.Printf("  failed %s: %s: %s\n", row.Link, row.Reason, row.Message)
+		}
+	}
+	if ctx.Err() != nil {
+		// An interrupt stops the browser; what was found is already stored.
+		return errors.New("interrupted")
+	}
+	return err
+}
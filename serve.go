@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/controllers"
+	"github.com/yourusername/car-listing-service/metrics"
+	"github.com/yourusername/car-listing-service/middleware"
+	"github.com/yourusername/car-listing-service/migrations"
+	"github.com/yourusername/car-listing-service/routes"
+	"github.com/yourusername/car-listing-service/services"
+	"github.com/yourusername/car-listing-service/tracing"
+	"github.com/yourusername/car-listing-service/validation"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+func setupRouter(ctx context.Context, cfg *config.Config, carService services.CarService, healthController *controllers.HealthController) (*gin.Engine, error) {
+	if cfg.Environment == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Recovery())
+	router.Use(otelgin.Middleware(tracing.ServiceName))
+	router.Use(middleware.Logger())
+	router.Use(middleware.Metrics())
+	router.Use(middleware.CORS())
+	router.Use(middleware.ErrorHandler())
+	router.NoRoute(middleware.NoRoute)
+
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "healthy",
+			"service": "car-listing-service",
+		})
+	})
+
+	router.GET("/livez", healthController.Livez)
+	router.GET("/readyz", healthController.Readyz)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	if err := validation.Register(); err != nil {
+		return nil, err
+	}
+	carController := controllers.NewCarController(ctx, carService, cfg.BatchMaxOps, int64(cfg.ImportMaxBytes))
+
+	routes.SetupRoutes(router, carController)
+
+	return router, nil
+}
+
+func runServe(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	port := flags.String("port", cfg.ServerPort, "port to listen on")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close(context.Background())
+
+	migrator, err := migrations.New(a.db)
+	if err != nil {
+		return err
+	}
+	if cfg.DBAutoMigrate {
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+	}
+	metrics.RegisterDBStats(a.db)
+
+	healthController := controllers.NewHealthController(a.db, migrator, a.service, cfg)
+	router, err := setupRouter(ctx, cfg, a.service, healthController)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:           ":" + *port,
+		Handler:        router,
+		ReadTimeout:    300 * time.Second,
+		WriteTimeout:   300 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+
+	if cfg.TrashPurgeEvery > 0 {
+		go purgeTrash(ctx, a.service, cfg.TrashPurgeEvery, cfg.TrashRetention)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("starting server", "port", *port, "environment", cfg.Environment)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	slog.Info("server exited")
+	return nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/migrations"
+	"github.com/yourusername/car-listing-service/repository"
+	_ "github.com/lib/pq"
+)
+
+// testDB connects to the Postgres database named by TEST_DATABASE_URL and
+// migrates it, skipping when the variable is unset.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// insertLegacyCar stores a row the way the first scraper did, without
+// currency or year, and deletes it when the test ends.
+func insertLegacyCar(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	link := fmt.Sprintf("https://www.facebook.com/marketplace/item/%d/", time.Now().UnixNano())
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO cars (title, price, location, mileage, link)
+		VALUES ('2016 Honda City 1.5 E', ' ₱390,000 ', 'Pasig', '70K km', $1)
+		RETURNING id`, link).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM cars WHERE id = $1", id) })
+	return id
+}
+
+func TestBackfillCarsFillsLegacyRows(t *testing.T) {
+	db := testDB(t)
+	id := insertLegacyCar(t, db)
+	repo := repository.NewCarRepository(db, repository.Timeouts{})
+	ctx := context.Background()
+
+	// Rows with NULL text columns must scan before they can be fixed.
+	before, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID on a legacy row: %v", err)
+	}
+	if before.Currency != "" || before.Year != "" {
+		t.Fatalf("legacy row read as currency %q, year %q, want both empty", before.Currency, before.Year)
+	}
+
+	updated, err := NewCarService(repo, config.ScraperConfig{}).BackfillCars(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated < 1 {
+		t.Errorf("updated = %d, want at least the legacy row", updated)
+	}
+
+	after, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Currency != "PHP" || after.Year != "2016" || after.Price != "₱390,000" {
+		t.Errorf("backfilled row = %+v, want PHP, 2016 and a trimmed price", after)
+	}
+	if after.Version != before.Version+1 {
+		t.Errorf("version = %d, want %d", after.Version, before.Version+1)
+	}
+
+	// Normalizing left the amount alone, so it is not a repricing.
+	var repriced int
+	if err := db.QueryRow("SELECT count(*) FROM car_price_history WHERE car_id = $1 AND change = 'repriced'", id).Scan(&repriced); err != nil {
+		t.Fatal(err)
+	}
+	if repriced != 0 {
+		t.Errorf("backfill recorded %d repricings, want none", repriced)
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BatchOutcome is the result of one batch operation. Car is the stored car
+// after a create or update; Err is nil when the operation succeeded.
+type BatchOutcome struct {
+	Car *models.Car
+	Err error
+}
+
+// BatchOpError reports the operation that aborted an atomic batch.
+type BatchOpError struct {
+	Index int
+	Op    models.BatchOp
+	Err   error
+}
+
+func (e *BatchOpError) Error() string {
+	return fmt.Sprintf("operation %d (%s): %v", e.Index, e.Op, e.Err)
+}
+
+func (e *BatchOpError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyBatch runs ops in order. In atomic mode they share one transaction
+// and the first failure rolls everything back and is returned as a
+// *BatchOpError. In best-effort mode each operation commits on its own and
+// failures are only reported in the outcomes.
+func (s *carService) ApplyBatch(ctx context.Context, mode models.BatchMode, ops []models.BatchOperation) ([]BatchOutcome, error) {
+	ctx, span := tracer.Start(ctx, "CarService.ApplyBatch", trace.WithAttributes(
+		attribute.String("batch.mode", string(mode)),
+		attribute.Int("batch.size", len(ops)),
+	))
+	defer span.End()
+
+	outcomes := make([]BatchOutcome, len(ops))
+	if mode != models.BatchAtomic {
+		for i, op := range ops {
+			outcomes[i] = applyBatchOp(ctx, s.repo, op)
+		}
+		return outcomes, nil
+	}
+
+	err := s.repo.WithTx(ctx, func(repo repository.CarRepository) error {
+		for i, op := range ops {
+			outcomes[i] = applyBatchOp(ctx, repo, op)
+			if outcomes[i].Err != nil {
+				return &BatchOpError{Index: i, Op: op.Op, Err: outcomes[i].Err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outcomes, nil
+}
+
+func applyBatchOp(ctx context.Context, repo repository.CarRepository, op models.BatchOperation) BatchOutcome {
+	switch op.Op {
+	case models.BatchCreate:
+		car := op.Car.Car()
+		NormalizeCar(&car)
+		if err := repo.Create(ctx, &car); err != nil {
+			return BatchOutcome{Err: err}
+		}
+		return BatchOutcome{Car: &car}
+	case models.BatchUpdate:
+		car := op.Car.Car()
+		car.ID = op.ID
+		car.Version = op.Version
+		NormalizeCar(&car)
+		if err := repo.Update(ctx, &car); err != nil {
+			return BatchOutcome{Err: err}
+		}
+		return BatchOutcome{Car: &car}
+	case models.BatchDelete:
+		return BatchOutcome{Err: repo.Delete(ctx, op.ID)}
+	default:
+		return BatchOutcome{Err: fmt.Errorf("unknown batch operation %q", op.Op)}
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// browserCandidates mirrors the locations chromedp searches when no explicit
+// executable is configured.
+var browserCandidates = []string{
+	"headless_shell",
+	"headless-shell",
+	"chromium",
+	"chromium-browser",
+	"google-chrome",
+	"google-chrome-stable",
+	"google-chrome-beta",
+	"google-chrome-unstable",
+	"/usr/bin/google-chrome",
+	"/usr/local/bin/chrome",
+	"/snap/bin/chromium",
+	"/Applications/Chromium.app/Contents/MacOS/Chromium",
+	"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+	"chrome",
+}
+
+var ErrBrowserNotFound = errors.New("no Chrome or Chromium executable found")
+
+// BrowserPath returns the browser executable the scraper would launch.
+func BrowserPath() (string, error) {
+	for _, candidate := range browserCandidates {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path, nil
+		}
+	}
+	return "", ErrBrowserNotFound
+}
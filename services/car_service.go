@@ -1,97 +1,438 @@
 package services
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
 
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/export"
+	"github.com/yourusername/car-listing-service/importer"
+	"github.com/yourusername/car-listing-service/logging"
+	"github.com/yourusername/car-listing-service/metrics"
 	"github.com/yourusername/car-listing-service/models"
 	"github.com/yourusername/car-listing-service/repository"
+	"github.com/yourusername/car-listing-service/tracing"
+	"github.com/yourusername/car-listing-service/valuation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/yourusername/car-listing-service/services")
+
 type CarService interface {
-	GetAllCars() ([]models.Car, error)
-	GetCarByID(id int) (*models.Car, error)
-	CreateCar(car *models.Car) error
-	UpdateCar(car *models.Car) error
-	DeleteCar(id int) error
-	ScrapeAndStoreCars() (int, error)
+	GetAllCars(ctx context.Context, filter models.CarFilter) ([]models.Car, error)
+	GetCarByID(ctx context.Context, id int) (*models.Car, error)
+	ExportCars(ctx context.Context, filter models.CarFilter, format export.Format, w io.Writer) (int, error)
+	CreateCar(ctx context.Context, car *models.Car) error
+	UpdateCar(ctx context.Context, car *models.Car) error
+	DeleteCar(ctx context.Context, id int) error
+	RestoreCar(ctx context.Context, id int) (*models.Car, error)
+	PurgeDeletedCars(ctx context.Context, retention time.Duration) (int, error)
+	GetStats(ctx context.Context, query models.StatsQuery) ([]models.StatsGroup, error)
+	GetPriceTrends(ctx context.Context, query models.TrendQuery) ([]models.TrendPoint, error)
+	Valuate(ctx context.Context, req models.ValuationRequest) (*models.Valuation, error)
+	TrainValuationModel(ctx context.Context) (*models.ValuationModelInfo, error)
+	ValuationModels(ctx context.Context) ([]models.ValuationModelInfo, error)
+	FraudCases(ctx context.Context, query models.FraudCaseQuery) ([]models.FraudCase, error)
+	ReviewFraud(ctx context.Context, id int, status models.FraudReviewStatus, note string) (*models.FraudReview, error)
+	ApplyBatch(ctx context.Context, mode models.BatchMode, ops []models.BatchOperation) ([]BatchOutcome, error)
+	ScrapeAndStoreCars(ctx context.Context, progress func(ScrapeResult)) (*ScrapeResult, error)
+	ImportListings(ctx context.Context, format importer.Format, r io.Reader, dryRun bool) (*models.ImportReport, error)
+	BackfillCars(ctx context.Context) (int, error)
+	ScrapeStatus() ScrapeStatus
+}
+
+// ScrapeStatus reports scrape activity since the process started.
+type ScrapeStatus struct {
+	Running       bool       `json:"running"`
+	LastJobID     string     `json:"last_job_id,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
 }
 
+// ScrapeResult summarises one scrape job. JobID tags every log record the
+// job writes.
+type ScrapeResult struct {
+	JobID    string             `json:"job_id"`
+	Batches  int                `json:"batches"`
+	Found    int                `json:"found"`
+	Inserted int                `json:"count"`
+	Skipped  int                `json:"skipped"`
+	Failed   int                `json:"failed"`
+	Failures []models.RowResult `json:"failures,omitempty"`
+}
+
+// maxReportedFailures bounds the failed rows a ScrapeResult lists; Failed
+// still counts all of them.
+const maxReportedFailures = 100
+
 type carService struct {
-	repo repository.CarRepository
+	repo          repository.CarRepository
+	scraperConfig config.ScraperConfig
+
+	mu           sync.Mutex
+	scrapeStatus ScrapeStatus
+	// valuation is the newest valuation model loaded so far.
+	valuation     *valuation.Model
+	valuationInfo models.ValuationModelInfo
+}
+
+func NewCarService(repo repository.CarRepository, scraperConfig config.ScraperConfig) CarService {
+	return &carService{repo: repo, scraperConfig: scraperConfig}
+}
+
+func (s *carService) GetAllCars(ctx context.Context, filter models.CarFilter) ([]models.Car, error) {
+	ctx, span := tracer.Start(ctx, "CarService.GetAllCars")
+	defer span.End()
+	return s.repo.GetAll(ctx, filter)
+}
+
+// ExportCars streams the cars matching filter to w in format and returns
+// how many were written.
+func (s *carService) ExportCars(ctx context.Context, filter models.CarFilter, format export.Format, w io.Writer) (count int, err error) {
+	ctx, span := tracer.Start(ctx, "CarService.ExportCars", trace.WithAttributes(attribute.String("export.format", string(format))))
+	defer func() {
+		span.SetAttributes(attribute.Int("export.rows", count))
+		tracing.End(span, err)
+	}()
+
+	writer, err := export.NewWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.repo.Each(ctx, filter, func(car *models.Car) error {
+		count++
+		return writer.Write(car)
+	})
+	if err != nil {
+		// Release the writer's buffers and temp files; the output is
+		// incomplete either way.
+		writer.Close()
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+func (s *carService) GetCarByID(ctx context.Context, id int) (*models.Car, error) {
+	ctx, span := tracer.Start(ctx, "CarService.GetCarByID")
+	defer span.End()
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *carService) CreateCar(ctx context.Context, car *models.Car) error {
+	ctx, span := tracer.Start(ctx, "CarService.CreateCar")
+	defer span.End()
+	NormalizeCar(car)
+	return s.repo.Create(ctx, car)
 }
 
-func NewCarService(repo repository.CarRepository) CarService {
-	return &carService{repo: repo}
+func (s *carService) UpdateCar(ctx context.Context, car *models.Car) error {
+	ctx, span := tracer.Start(ctx, "CarService.UpdateCar")
+	defer span.End()
+	NormalizeCar(car)
+	return s.repo.Update(ctx, car)
 }
 
-func (s *carService) GetAllCars() ([]models.Car, error) {
-	return s.repo.GetAll()
+func (s *carService) DeleteCar(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "CarService.DeleteCar")
+	defer span.End()
+	return s.repo.Delete(ctx, id)
 }
 
-func (s *carService) GetCarByID(id int) (*models.Car, error) {
-	return s.repo.GetByID(id)
+func (s *carService) RestoreCar(ctx context.Context, id int) (*models.Car, error) {
+	ctx, span := tracer.Start(ctx, "CarService.RestoreCar")
+	defer span.End()
+	return s.repo.Restore(ctx, id)
 }
 
-func (s *carService) CreateCar(car *models.Car) error {
-	return s.repo.Create(car)
+// PurgeDeletedCars permanently removes cars that have been in the trash for
+// longer than retention. Their links are blocked so they are never scraped
+// or imported again.
+func (s *carService) PurgeDeletedCars(ctx context.Context, retention time.Duration) (int, error) {
+	ctx, span := tracer.Start(ctx, "CarService.PurgeDeletedCars")
+	defer span.End()
+
+	purged, err := s.repo.Purge(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		slog.InfoContext(ctx, "purged deleted cars", "purged", purged, "retention", retention)
+	}
+	return purged, nil
 }
 
-func (s *carService) UpdateCar(car *models.Car) error {
-	return s.repo.Update(car)
+// GetStats aggregates live listings per group as query asks.
+func (s *carService) GetStats(ctx context.Context, query models.StatsQuery) ([]models.StatsGroup, error) {
+	ctx, span := tracer.Start(ctx, "CarService.GetStats")
+	defer span.End()
+
+	return s.repo.Stats(ctx, query)
 }
 
-func (s *carService) DeleteCar(id int) error {
-	return s.repo.Delete(id)
+// GetPriceTrends charts median asking prices and listing volume per period.
+// It reflects the listings stored up to the last scrape or import.
+func (s *carService) GetPriceTrends(ctx context.Context, query models.TrendQuery) ([]models.TrendPoint, error) {
+	ctx, span := tracer.Start(ctx, "CarService.GetPriceTrends")
+	defer span.End()
+
+	return s.repo.PriceTrends(ctx, query)
+}
+
+// refreshDerived recomputes the data derived from stored listings once new
+// ones have arrived. Failures are logged; the listings themselves are stored
+// and the next refresh catches up.
+func (s *carService) refreshDerived(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "CarService.refreshDerived")
+
+	scored, scoreErr := s.repo.ScoreDeals(ctx)
+	if scoreErr != nil {
+		slog.ErrorContext(ctx, "failed to score deals", "error", scoreErr)
+	} else {
+		slog.InfoContext(ctx, "scored deals", "scored", scored)
+	}
+
+	fraudErr := s.detectFraud(ctx)
+	if fraudErr != nil {
+		slog.ErrorContext(ctx, "failed to detect fraud", "error", fraudErr)
+	}
+
+	trendsErr := s.repo.RefreshPriceTrends(ctx)
+	if trendsErr != nil {
+		slog.ErrorContext(ctx, "failed to refresh price trends", "error", trendsErr)
+	}
+	tracing.End(span, errors.Join(scoreErr, fraudErr, trendsErr))
 }
 
-func (s *carService) ScrapeAndStoreCars() (int, error) {
+// ScrapeAndStoreCars runs one scrape job, storing each batch as it arrives.
+// progress, if non-nil, is called with the running totals after every batch.
+// Cancelling ctx stops the browser; listings found until then are still
+// stored.
+func (s *carService) ScrapeAndStoreCars(ctx context.Context, progress func(ScrapeResult)) (result *ScrapeResult, err error) {
+	result = &ScrapeResult{JobID: logging.NewID()}
+
+	ctx = logging.WithJobID(ctx, result.JobID)
+	ctx, span := tracer.Start(ctx, "CarService.ScrapeAndStoreCars", trace.WithAttributes(attribute.String("scrape.job_id", result.JobID)))
+	defer func() {
+		span.SetAttributes(attribute.Int("scrape.inserted", result.Inserted))
+		tracing.End(span, err)
+	}()
+
+	slog.InfoContext(ctx, "scrape job started")
+	s.recordScrapeStart(result.JobID)
+	defer func() { s.recordScrapeEnd(err) }()
+
 	resultsChan := make(chan []models.Car)
 	doneChan := make(chan error)
+	var storeErr error
 
 	go func() {
-		err := ScrapeCars(resultsChan)
+		err := ScrapeCarsWithConfig(ctx, s.scraperConfig, resultsChan)
 		close(resultsChan)
 		doneChan <- err
 	}()
 
-	totalCount := 0
+	// Storing is bounded by the repository timeouts and finishes even once
+	// the job is stopped, so nothing already scraped is lost.
+	storeCtx := context.WithoutCancel(ctx)
 
 	for batch := range resultsChan {
 		if len(batch) == 0 {
 			continue
 		}
 
-		links := make([]string, len(batch))
-		for i, car := range batch {
-			links[i] = car.Link
-		}
-
-		existingLinks, err := s.repo.FindExistingLinks(links)
+		stored, err := s.storeBatch(storeCtx, batch)
 		if err != nil {
-			log.Printf("Error checking existing links: %v", err)
-			continue
+			slog.ErrorContext(ctx, "failed to store batch", "batch_size", len(batch), "error", err)
+			if storeErr == nil {
+				storeErr = err
+			}
+			result.Failed += len(batch)
+			metrics.ScraperStoreFailures.WithLabelValues("batch_failed").Add(float64(len(batch)))
+		} else {
+			s.recordStored(ctx, result, stored)
 		}
 
-		var newCars []models.Car
-		for _, car := range batch {
-			if !existingLinks[car.Link] {
-				newCars = append(newCars, car)
-			}
+		result.Batches++
+		result.Found += len(batch)
+		if progress != nil {
+			progress(*result)
 		}
+	}
 
-		if len(newCars) == 0 {
-			continue
+	err = <-doneChan
+	if result.Inserted > 0 {
+		s.refreshDerived(storeCtx)
+	}
+	if err == nil && storeErr != nil {
+		err = fmt.Errorf("store scraped listings: %w", storeErr)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "scrape job failed", "inserted", result.Inserted, "error", err)
+	} else {
+		slog.InfoContext(ctx, "scrape job finished", "inserted", result.Inserted, "skipped", result.Skipped, "failed", result.Failed)
+	}
+	return result, err
+}
+
+// recordStored adds one stored batch to the job totals. Rows are numbered in
+// the order the job found them.
+func (s *carService) recordStored(ctx context.Context, result *ScrapeResult, stored models.InsertResult) {
+	result.Inserted += stored.Inserted
+	result.Skipped += len(stored.Skipped)
+	result.Failed += len(stored.Failed)
+	metrics.ScraperInserted.Add(float64(stored.Inserted))
+
+	for _, row := range stored.Failed {
+		metrics.ScraperStoreFailures.WithLabelValues(row.Reason).Inc()
+		if len(result.Failures) < maxReportedFailures {
+			row.Index += result.Found
+			result.Failures = append(result.Failures, row)
 		}
+	}
+	if len(stored.Failed) > 0 {
+		slog.WarnContext(ctx, "listings failed to store",
+			"failed", len(stored.Failed), "first_link", stored.Failed[0].Link, "first_reason", stored.Failed[0].Reason)
+	}
+}
 
-		count, err := s.repo.InsertBatch(newCars)
-		if err != nil {
-			log.Printf("Error inserting batch: %v", err)
+func (s *carService) ScrapeStatus() ScrapeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scrapeStatus
+}
+
+func (s *carService) recordScrapeStart(jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scrapeStatus.Running = true
+	s.scrapeStatus.LastJobID = jobID
+}
+
+func (s *carService) recordScrapeEnd(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.scrapeStatus.Running = false
+	if err != nil {
+		s.scrapeStatus.LastFailureAt = &now
+		s.scrapeStatus.LastError = err.Error()
+	} else {
+		s.scrapeStatus.LastSuccessAt = &now
+	}
+}
+
+// storeBatch inserts the listings of one scrape or import batch whose links
+// are not already known. Row indexes in the result refer to positions in
+// batch.
+func (s *carService) storeBatch(ctx context.Context, batch []models.Car) (result models.InsertResult, err error) {
+	ctx, span := tracer.Start(ctx, "CarService.storeBatch", trace.WithAttributes(attribute.Int("batch.size", len(batch))))
+	defer func() { tracing.End(span, err) }()
+
+	links := make([]string, len(batch))
+	for i := range batch {
+		NormalizeCar(&batch[i])
+		links[i] = batch[i].Link
+	}
+
+	existingLinks, err := s.repo.FindExistingLinks(ctx, links)
+	if err != nil {
+		return result, err
+	}
+
+	var newCars []models.Car
+	var newIndex []int
+	for i, car := range batch {
+		if existingLinks[car.Link] {
+			result.Skipped = append(result.Skipped, models.RowResult{Index: i, Link: car.Link, Reason: models.SkipKnownLink})
 			continue
 		}
+		newCars = append(newCars, car)
+		newIndex = append(newIndex, i)
+	}
+
+	if len(newCars) == 0 {
+		return result, nil
+	}
+
+	inserted, err := s.insertCars(ctx, newCars)
+	if err != nil {
+		return result, err
+	}
+
+	result.Inserted = inserted.Inserted
+	for _, row := range inserted.Skipped {
+		row.Index = newIndex[row.Index]
+		result.Skipped = append(result.Skipped, row)
+	}
+	for _, row := range inserted.Failed {
+		row.Index = newIndex[row.Index]
+		result.Failed = append(result.Failed, row)
+	}
+	return result, nil
+}
 
-		totalCount += count
+// insertCars bulk-loads batches of at least CopyThreshold cars with COPY and
+// inserts smaller ones row by row. If the COPY is rejected because of a bad
+// row, the batch is retried row by row so the offending rows are reported
+// and the rest still stored.
+func (s *carService) insertCars(ctx context.Context, cars []models.Car) (models.InsertResult, error) {
+	threshold := s.scraperConfig.CopyThreshold
+	if threshold <= 0 || len(cars) < threshold {
+		return s.repo.InsertBatch(ctx, cars)
+	}
+
+	result, err := s.repo.CopyInsert(ctx, cars)
+	if err == nil {
+		return result, nil
+	}
+	if apperror.KindOf(err) == apperror.KindUnavailable || ctx.Err() != nil {
+		return result, err
+	}
+
+	slog.WarnContext(ctx, "bulk copy rejected, retrying row by row", "batch_size", len(cars), "error", err)
+	return s.repo.InsertBatch(ctx, cars)
+}
+
+// BackfillCars re-normalizes every live listing, read through a cursor, and
+// saves the ones that changed, filling in currency and year on rows scraped
+// before they were derived. It then recomputes deal scores and price trends.
+func (s *carService) BackfillCars(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "CarService.BackfillCars")
+	defer span.End()
+
+	scanned, updated := 0, 0
+	err := s.repo.Each(ctx, models.CarFilter{Scams: models.ScamsInclude}, func(car *models.Car) error {
+		scanned++
+		normalized := *car
+		NormalizeCar(&normalized)
+		if normalized == *car {
+			return nil
+		}
+		if err := s.repo.Update(ctx, &normalized); err != nil {
+			if apperror.KindOf(err) == apperror.KindPreconditionFailed {
+				slog.WarnContext(ctx, "skipping car edited during backfill", "car_id", car.ID)
+				return nil
+			}
+			return err
+		}
+		updated++
+		return nil
+	})
+	if err != nil {
+		return updated, err
 	}
 
-	err := <-doneChan
-	return totalCount, err
+	slog.InfoContext(ctx, "backfill finished", "scanned", scanned, "updated", updated)
+	s.refreshDerived(ctx)
+	return updated, nil
 }
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/export"
+	"github.com/yourusername/car-listing-service/models"
+)
+
+// failingWriter fails every write, like a client that went away.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("client went away")
+}
+
+func TestExportCars(t *testing.T) {
+	repo := &fakeRepository{cars: []models.Car{
+		{ID: 2, Title: "2018 Toyota Vios", Link: "https://example.com/2"},
+		{ID: 1, Title: "2016 Honda City", Link: "https://example.com/1"},
+	}}
+	s := NewCarService(repo, config.ScraperConfig{})
+
+	var buf bytes.Buffer
+	count, err := s.ExportCars(context.Background(), models.CarFilter{}, export.NDJSON, &buf)
+	if err != nil || count != 2 {
+		t.Fatalf("ExportCars = %d, %v, want 2 rows", count, err)
+	}
+	var ids []int
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var car models.Car
+		if err := json.Unmarshal(scanner.Bytes(), &car); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		ids = append(ids, car.ID)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 1 {
+		t.Errorf("exported IDs %v, want [2 1] in cursor order", ids)
+	}
+
+	if _, err := s.ExportCars(context.Background(), models.CarFilter{}, export.NDJSON, failingWriter{}); err == nil {
+		t.Error("export to a failing writer succeeded")
+	}
+}
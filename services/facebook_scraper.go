@@ -3,28 +3,29 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"os"
 	"time"
 
 	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/metrics"
 	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/tracing"
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	cookieFile = "facebook_cookies.json"
-	targetURL  = "https://www.facebook.com/marketplace/manila/cars?minPrice=350000&exact=false"
+	stopReasonMaxScrolls      = "max_scrolls"
+	stopReasonMaxDuration     = "max_duration"
+	stopReasonEndOfFeed       = "end_of_feed"
+	stopReasonReplayExhausted = "replay_exhausted"
+	stopReasonError           = "error"
 )
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
 // ScrollState tracks the state of scrolling and end detection
 type ScrollState struct {
 	currentScroll           int
@@ -39,72 +40,161 @@ type ScrollState struct {
 	totalNewItems           int
 	totalDuplicates         int
 	startTime               time.Time
+	now                     func() time.Time
+}
+
+func newScrollState(scraperConfig config.ScraperConfig, now func() time.Time) *ScrollState {
+	return &ScrollState{
+		currentScroll: 0,
+		currentDelay:  scraperConfig.InitialDelay,
+		seenURLs:      make(map[string]bool, 10000),
+		startTime:     now(),
+		now:           now,
+	}
+}
+
+func (s *ScrollState) elapsed() time.Duration {
+	return s.now().Sub(s.startTime)
 }
 
-func ScrapeCars(resultsChan chan<- []models.Car) error {
+func ScrapeCars(ctx context.Context, resultsChan chan<- []models.Car) error {
 	// Load configuration
 	cfg := config.LoadConfig()
-	scraperConfig := cfg.Scraper
+	return ScrapeCarsWithConfig(ctx, cfg.Scraper, resultsChan)
+}
+
+// ScrapeCarsWithConfig runs a scrape session against scraperConfig.TargetURL,
+// logging in through scraperConfig.LoginURL when no saved cookies are usable.
+func ScrapeCarsWithConfig(ctx context.Context, scraperConfig config.ScraperConfig, resultsChan chan<- []models.Car) (err error) {
+	ctx, span := tracer.Start(ctx, "scraper.job", trace.WithAttributes(
+		attribute.String("scraper.target_url", scraperConfig.TargetURL),
+		attribute.Bool("scraper.replay", scraperConfig.ReplayDir != ""),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	start := time.Now()
+	defer func() {
+		status := "success"
+		if err != nil {
+			status = "error"
+			metrics.ScraperStops.WithLabelValues(stopReasonError).Inc()
+		}
+		metrics.ScraperJobDuration.WithLabelValues(status).Observe(time.Since(start).Seconds())
+	}()
+
+	if scraperConfig.ReplayDir != "" {
+		return replayScrape(ctx, scraperConfig.ReplayDir, resultsChan)
+	}
 
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", false),
+		chromedp.Flag("headless", scraperConfig.Headless),
 		chromedp.UserAgent("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"),
 	)
 
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	defer cancel()
+	// The allocator inherits the job's trace span, so every chromedp action
+	// below is parented to the job. Cancelling the job closes Chrome.
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, opts...)
+	defer cancelAlloc()
 
 	ctx, cancel := chromedp.NewContext(allocCtx)
 	defer cancel()
 
-	if err := loadCookies(ctx, cookieFile); err != nil {
-		if err := login(ctx); err != nil {
+	if err := loadCookies(ctx, scraperConfig.CookieFile); err != nil {
+		metrics.ScraperLoginAttempts.Inc()
+		if err := login(ctx, scraperConfig); err != nil {
+			metrics.ScraperLoginFailures.Inc()
 			return err
 		}
-		if err := saveCookies(ctx, cookieFile); err != nil {
-			log.Printf("Warning: Failed to save cookies: %v", err)
+		if err := saveCookies(ctx, scraperConfig.CookieFile); err != nil {
+			slog.WarnContext(ctx, "failed to save cookies", "error", err)
 		}
 	}
 
-	// Initialize scroll state
-	state := &ScrollState{
-		currentScroll: 0,
-		currentDelay:  scraperConfig.InitialDelay,
-		seenURLs:      make(map[string]bool, 10000),
-		startTime:     time.Now(),
+	var source ListingSource = &chromeSource{}
+	if scraperConfig.RecordDir != "" {
+		recorder, err := newRecordingSource(ctx, source, scraperConfig.RecordDir, scraperConfig)
+		if err != nil {
+			return err
+		}
+		source = recorder
 	}
 
+	// Initialize scroll state
+	state := newScrollState(scraperConfig, time.Now)
+
 	return chromedp.Run(ctx,
-		chromedp.Navigate(targetURL),
+		chromedp.Navigate(scraperConfig.TargetURL),
 		chromedp.Sleep(5*time.Second),
 		chromedp.ActionFunc(func(ctx context.Context) error {
-			for !shouldStopScraping(state, scraperConfig) {
-				if err := performScrollCycle(ctx, state, scraperConfig, resultsChan); err != nil {
-					return err
-				}
-			}
-			logSummary(state)
-			return nil
+			return runScrollLoop(ctx, source, state, scraperConfig, resultsChan)
 		}),
 	)
 }
 
-func performScrollCycle(ctx context.Context, state *ScrollState, config config.ScraperConfig, resultsChan chan<- []models.Car) error {
-	prevDOMCount, prevScrollY := captureDOMState(ctx)
+// replayScrape reruns a recorded session with the scraper config it was
+// recorded under, so extraction and stop decisions match the original run.
+func replayScrape(ctx context.Context, dir string, resultsChan chan<- []models.Car) error {
+	source, err := newReplaySource(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	state := newScrollState(source.session.Config, source.Now)
+	return runScrollLoop(ctx, source, state, source.session.Config, resultsChan)
+}
 
-	if err := chromedp.Run(ctx,
-		chromedp.Evaluate(`window.scrollBy(0, window.innerHeight)`, nil),
-		chromedp.Sleep(state.currentDelay),
-	); err != nil {
+func runScrollLoop(ctx context.Context, source ListingSource, state *ScrollState, config config.ScraperConfig, resultsChan chan<- []models.Car) error {
+	reason := stopReason(state, config)
+	for reason == "" {
+		// Replays never touch the browser, so check for a stopped job here.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := performScrollCycle(ctx, source, state, config, resultsChan); err != nil {
+			if errors.Is(err, errReplayExhausted) {
+				reason = stopReasonReplayExhausted
+				break
+			}
+			return err
+		}
+		reason = stopReason(state, config)
+	}
+	metrics.ScraperStops.WithLabelValues(reason).Inc()
+	logSummary(ctx, state, reason)
+	return nil
+}
+
+func performScrollCycle(ctx context.Context, source ListingSource, state *ScrollState, config config.ScraperConfig, resultsChan chan<- []models.Car) (err error) {
+	ctx, span := tracer.Start(ctx, "scraper.scroll_cycle", trace.WithAttributes(
+		attribute.Int("scraper.cycle", state.currentScroll+1),
+		attribute.Int64("scraper.delay_ms", state.currentDelay.Milliseconds()),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	prevDOMCount, prevScrollY := source.DOMState(ctx)
+
+	if err := source.Scroll(ctx, state.currentDelay); err != nil {
 		return err
 	}
 
 	state.currentScroll++
-	currDOMCount, currScrollY := captureDOMState(ctx)
+	metrics.ScraperScrollCycles.Inc()
+	currDOMCount, currScrollY := source.DOMState(ctx)
 	updateScrollSignals(state, prevDOMCount, currDOMCount, prevScrollY, currScrollY)
 
-	allListings := extractListings(ctx)
+	allListings := source.Listings(ctx)
 	newListings := filterDuplicates(allListings, state)
+	if config.FetchSellers {
+		lookupSellers(ctx, source, newListings)
+	}
+	metrics.ScraperItemsExtracted.Add(float64(len(allListings)))
+	metrics.ScraperNewItems.Add(float64(len(newListings)))
+	metrics.ScraperDuplicates.Add(float64(state.totalDuplicates))
+	span.SetAttributes(
+		attribute.Int("scraper.dom_count", currDOMCount),
+		attribute.Int("scraper.items", len(allListings)),
+		attribute.Int("scraper.new_items", len(newListings)),
+	)
 
 	if len(newListings) == 0 {
 		state.consecutiveNoNewItems++
@@ -121,67 +211,23 @@ func performScrollCycle(ctx context.Context, state *ScrollState, config config.S
 		len(allListings),
 		config,
 	)
+	metrics.ScraperDelay.Set(state.currentDelay.Seconds())
 
 	if state.currentScroll%config.ExtractionInterval == 0 {
-		logProgress(state, config)
+		logProgress(ctx, state, config)
 	}
 
 	return nil
 }
 
-func captureDOMState(ctx context.Context) (domCount, scrollY int) {
-	chromedp.Run(ctx,
-		chromedp.Evaluate(`document.querySelectorAll("a[href*='/marketplace/item/']").length`, &domCount),
-		chromedp.Evaluate(`window.scrollY`, &scrollY),
-	)
-	return
-}
-
-func extractListings(ctx context.Context) []models.Car {
-	var listings []models.Car
-	chromedp.Run(ctx, chromedp.Evaluate(`
-		Array.from(document.querySelectorAll("a[href*='/marketplace/item/']")).map(a => {
-			const text = a.innerText.split('\n');
-			let price = "";
-			let title = "";
-			let location = "";
-			let mileage = "";
-
-			text.forEach(line => {
-				if (line.includes("₱") || line.includes("PHP") || line.includes("$")) {
-					price = line;
-				} else if (line.toLowerCase().includes("km")) {
-					mileage = line;
-				} else if (title === "" && line.length > 5) {
-					title = line;
-				} else if (location === "" && title !== "" && line !== price && line !== mileage) {
-					location = line;
-				}
-			});
-
-			const url = new URL(a.href);
-			const cleanLink = url.origin + url.pathname;
-
-			return {
-				title: title,
-				price: price,
-				location: location,
-				mileage: mileage,
-				link: cleanLink
-			};
-		})
-	`, &listings))
-
-	return listings
-}
-
-func shouldStopScraping(state *ScrollState, config config.ScraperConfig) bool {
+// stopReason returns why the session should stop, or "" to keep scrolling.
+func stopReason(state *ScrollState, config config.ScraperConfig) string {
 	if state.currentScroll >= config.MaxScrolls {
-		return true
+		return stopReasonMaxScrolls
 	}
 
-	if time.Since(state.startTime) >= config.MaxDuration {
-		return true
+	if state.elapsed() >= config.MaxDuration {
+		return stopReasonMaxDuration
 	}
 
 	triggeredCount := 0
@@ -198,7 +244,10 @@ func shouldStopScraping(state *ScrollState, config config.ScraperConfig) bool {
 		triggeredCount++
 	}
 
-	return triggeredCount >= 2
+	if triggeredCount >= 2 {
+		return stopReasonEndOfFeed
+	}
+	return ""
 }
 
 func updateScrollSignals(state *ScrollState, prevDOMCount, currDOMCount, prevScrollY, currScrollY int) {
@@ -239,6 +288,26 @@ func filterDuplicates(allListings []models.Car, state *ScrollState) []models.Car
 	return newListings
 }
 
+// lookupSellers fills in the seller of each listing when the source can look
+// it up. A failed lookup leaves the seller empty.
+func lookupSellers(ctx context.Context, source ListingSource, listings []models.Car) {
+	lookup, ok := source.(sellerSource)
+	if !ok {
+		return
+	}
+	for i := range listings {
+		if ctx.Err() != nil {
+			return
+		}
+		seller, err := lookup.Seller(ctx, listings[i].Link)
+		if err != nil {
+			slog.WarnContext(ctx, "seller lookup failed", "link", listings[i].Link, "error", err)
+			continue
+		}
+		listings[i].Seller = seller
+	}
+}
+
 func calculateAdaptiveDelay(
 	currentDelay time.Duration,
 	newItemsCount int,
@@ -273,41 +342,47 @@ func calculateAdaptiveDelay(
 	return currentDelay
 }
 
-func logProgress(state *ScrollState, config config.ScraperConfig) {
-	elapsed := time.Since(state.startTime)
-	log.Printf("Progress: Scroll %d/%d | Total items: %d | New in batch: %d | Duplicates: %d | Delay: %v | Elapsed: %v",
-		state.currentScroll,
-		config.MaxScrolls,
-		state.totalItemsFound,
-		state.totalNewItems,
-		state.totalDuplicates,
-		state.currentDelay,
-		elapsed.Round(time.Second),
+func logProgress(ctx context.Context, state *ScrollState, config config.ScraperConfig) {
+	elapsed := state.elapsed()
+	slog.InfoContext(ctx, "scrape progress",
+		"scroll", state.currentScroll,
+		"max_scrolls", config.MaxScrolls,
+		"total_items", state.totalItemsFound,
+		"new_in_batch", state.totalNewItems,
+		"duplicates", state.totalDuplicates,
+		"delay", state.currentDelay,
+		"elapsed", elapsed.Round(time.Second),
 	)
 	state.totalNewItems = 0
 }
 
-func logSummary(state *ScrollState) {
-	elapsed := time.Since(state.startTime)
-	log.Printf("Scraping Complete!")
-	log.Printf("Total Scrolls: %d", state.currentScroll)
-	log.Printf("Total Items Found: %d", state.totalItemsFound)
-	log.Printf("Total Duration: %v", elapsed.Round(time.Second))
+func logSummary(ctx context.Context, state *ScrollState, reason string) {
+	elapsed := state.elapsed()
+	attrs := []any{
+		"stop_reason", reason,
+		"total_scrolls", state.currentScroll,
+		"total_items", state.totalItemsFound,
+		"duration", elapsed.Round(time.Second),
+	}
 	if elapsed.Minutes() > 0 {
-		log.Printf("Average Items/Minute: %.1f", float64(state.totalItemsFound)/(elapsed.Minutes()))
+		attrs = append(attrs, "items_per_minute", float64(state.totalItemsFound)/elapsed.Minutes())
 	}
+	slog.InfoContext(ctx, "scraping complete", attrs...)
 }
 
-func login(ctx context.Context) error {
-	email := getEnv("FACEBOOK_EMAIL", "")
-	password := getEnv("FACEBOOK_PASSWORD", "")
+func login(ctx context.Context, scraperConfig config.ScraperConfig) (err error) {
+	ctx, span := tracer.Start(ctx, "scraper.login")
+	defer func() { tracing.End(span, err) }()
+
+	email := scraperConfig.FacebookEmail
+	password := scraperConfig.FacebookPassword
 
 	if email == "" || password == "" {
-		log.Fatal("FACEBOOK_EMAIL and FACEBOOK_PASSWORD must be set in environment variables")
+		return errors.New("FACEBOOK_EMAIL and FACEBOOK_PASSWORD must be set in environment variables")
 	}
 
 	return chromedp.Run(ctx,
-		chromedp.Navigate("https://www.facebook.com/login"),
+		chromedp.Navigate(scraperConfig.LoginURL),
 		chromedp.WaitVisible("input#email", chromedp.ByQuery),
 		chromedp.SendKeys("input#email", email, chromedp.ByQuery),
 		chromedp.SendKeys("input#pass", password, chromedp.ByQuery),
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/metrics"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/testutil/fakemarketplace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// scrapeFake runs a full scrape session in headless Chrome against a fake
+// Marketplace and returns every listing sent on the results channel. The
+// options adjust the config before the session starts.
+func scrapeFake(t *testing.T, server *fakemarketplace.Server, maxScrolls int, options ...func(*config.ScraperConfig)) ([]models.Car, config.ScraperConfig) {
+	t.Helper()
+	cfg := config.ScraperConfig{
+		MaxScrolls:              maxScrolls,
+		MaxDuration:             2 * time.Minute,
+		InitialDelay:            300 * time.Millisecond,
+		MinDelay:                200 * time.Millisecond,
+		MaxDelay:                500 * time.Millisecond,
+		MaxConsecutiveNoNew:     4,
+		MaxConsecutiveUnchanged: 4,
+		ExtractionInterval:      5,
+		CookieFile:              filepath.Join(t.TempDir(), "cookies.json"),
+	}
+	server.Configure(&cfg)
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	resultsChan := make(chan []models.Car)
+	done := make(chan error, 1)
+	go func() {
+		done <- ScrapeCarsWithConfig(ctx, cfg, resultsChan)
+		close(resultsChan)
+	}()
+
+	var cars []models.Car
+	for batch := range resultsChan {
+		cars = append(cars, batch...)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ScrapeCarsWithConfig: %v", err)
+	}
+	return cars, cfg
+}
+
+func skipWithoutBrowser(t *testing.T) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("end-to-end scrape skipped in short mode")
+	}
+	if _, err := BrowserPath(); err != nil {
+		t.Skip(err)
+	}
+}
+
+// checkListings verifies every listing of the feed was found exactly once
+// with its fields parsed.
+func checkListings(t *testing.T, server *fakemarketplace.Server, cars []models.Car, total int) {
+	t.Helper()
+	seen := make(map[string]bool)
+	for _, car := range cars {
+		if seen[car.Link] {
+			t.Errorf("link %s sent twice", car.Link)
+		}
+		seen[car.Link] = true
+		if car.Title == "" || car.Price == "" || car.Mileage == "" {
+			t.Errorf("listing %+v is missing fields", car)
+		}
+	}
+	for id := 0; id < total; id++ {
+		link := fmt.Sprintf("%s/marketplace/item/%d/", server.URL(), id)
+		if !seen[link] {
+			t.Errorf("listing %d (%s) not found", id, link)
+		}
+	}
+	if len(seen) != total {
+		t.Errorf("found %d unique listings, want %d", len(seen), total)
+	}
+}
+
+func TestScrapeFakeMarketplaceToEndOfFeed(t *testing.T) {
+	skipWithoutBrowser(t)
+
+	const listings = 60
+	server := fakemarketplace.New(fakemarketplace.Options{
+		Listings:     listings,
+		PageSize:     12,
+		Duplicates:   3,
+		LoadDelay:    100 * time.Millisecond,
+		EndOfFeed:    fakemarketplace.EndStop,
+		RequireLogin: true,
+	})
+	defer server.Close()
+
+	endOfFeed := testutil.ToFloat64(metrics.ScraperStops.WithLabelValues(stopReasonEndOfFeed))
+	cars, cfg := scrapeFake(t, server, 200)
+
+	// No cookies were saved yet, so the session logs in and saves them.
+	if attempts := server.LoginAttempts(); attempts != 1 {
+		t.Errorf("login attempts = %d, want 1", attempts)
+	}
+	if _, err := os.Stat(cfg.CookieFile); err != nil {
+		t.Errorf("cookies not saved: %v", err)
+	}
+
+	checkListings(t, server, cars, listings)
+	if got := testutil.ToFloat64(metrics.ScraperStops.WithLabelValues(stopReasonEndOfFeed)) - endOfFeed; got != 1 {
+		t.Errorf("end_of_feed stops = %v, want 1", got)
+	}
+}
+
+func TestScrapeFakeMarketplaceRepeatingFeed(t *testing.T) {
+	skipWithoutBrowser(t)
+
+	const listings = 30
+	server := fakemarketplace.New(fakemarketplace.Options{
+		Listings:  listings,
+		PageSize:  10,
+		EndOfFeed: fakemarketplace.EndRepeat,
+	})
+	defer server.Close()
+
+	stops := func() float64 {
+		return testutil.ToFloat64(metrics.ScraperStops.WithLabelValues(stopReasonEndOfFeed)) +
+			testutil.ToFloat64(metrics.ScraperStops.WithLabelValues(stopReasonMaxScrolls))
+	}
+	before := stops()
+	cars, _ := scrapeFake(t, server, 40)
+
+	// The feed keeps growing with listings already seen; the session must
+	// still end, on the stop signals or the scroll cap, with each listing
+	// sent once.
+	checkListings(t, server, cars, listings)
+	if pages := server.PagesServed(); pages <= listings/10+1 {
+		t.Errorf("pages served = %d, want repeats after the %d unique pages", pages, listings/10)
+	}
+	if got := stops() - before; got != 1 {
+		t.Errorf("end_of_feed or max_scrolls stops = %v, want 1", got)
+	}
+}
+
+func TestScrapeFakeMarketplaceSellers(t *testing.T) {
+	skipWithoutBrowser(t)
+
+	const listings = 12
+	server := fakemarketplace.New(fakemarketplace.Options{
+		Listings: listings,
+		PageSize: 6,
+		Sellers:  4,
+	})
+	defer server.Close()
+
+	cars, _ := scrapeFake(t, server, 20, func(cfg *config.ScraperConfig) { cfg.FetchSellers = true })
+
+	checkListings(t, server, cars, listings)
+	for _, car := range cars {
+		var id int
+		if _, err := fmt.Sscanf(car.Link, server.URL()+"/marketplace/item/%d/", &id); err != nil {
+			t.Fatalf("unexpected link %s: %v", car.Link, err)
+		}
+		if want := server.Seller(id); car.Seller != want {
+			t.Errorf("seller of listing %d = %q, want %q", id, car.Seller, want)
+		}
+	}
+}
+
+func TestScrollLoopMetrics(t *testing.T) {
+	cfg := config.ScraperConfig{
+		MaxScrolls:              50,
+		MaxDuration:             time.Hour,
+		InitialDelay:            time.Second,
+		MinDelay:                time.Second,
+		MaxDelay:                3 * time.Second,
+		MaxConsecutiveNoNew:     2,
+		MaxConsecutiveUnchanged: 2,
+		ExtractionInterval:      5,
+	}
+	counters := map[string]prometheus.Counter{
+		"scroll cycles":   metrics.ScraperScrollCycles,
+		"items extracted": metrics.ScraperItemsExtracted,
+		"new items":       metrics.ScraperNewItems,
+		"duplicates":      metrics.ScraperDuplicates,
+		"end_of_feed":     metrics.ScraperStops.WithLabelValues(stopReasonEndOfFeed),
+	}
+	before := make(map[string]float64, len(counters))
+	for name, counter := range counters {
+		before[name] = testutil.ToFloat64(counter)
+	}
+
+	// Three pages of four: the cycles see 8, 12, 12 and 12 listings, of
+	// which 8, 4, 0 and 0 are new.
+	collect(t, func(resultsChan chan<- []models.Car) error {
+		return runScrollLoop(context.Background(), newPagedSource(3, 4), newScrollState(cfg, time.Now), cfg, resultsChan)
+	})
+
+	want := map[string]float64{
+		"scroll cycles":   4,
+		"items extracted": 44,
+		"new items":       12,
+		"duplicates":      32,
+		"end_of_feed":     1,
+	}
+	for name, counter := range counters {
+		if got := testutil.ToFloat64(counter) - before[name]; got != want[name] {
+			t.Errorf("%s grew by %v, want %v", name, got, want[name])
+		}
+	}
+	// Mostly duplicates once, then nothing new twice: 1s + 200ms + 2 * 500ms.
+	if got := testutil.ToFloat64(metrics.ScraperDelay); got != 2.2 {
+		t.Errorf("delay = %vs, want 2.2s", got)
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	for _, series := range []string{
+		"car_listing_scraper_scroll_cycles_total",
+		`car_listing_scraper_stops_total{reason="end_of_feed"}`,
+		"car_listing_scraper_delay_seconds 2.2",
+	} {
+		if !strings.Contains(recorder.Body.String(), series) {
+			t.Errorf("/metrics does not expose %s", series)
+		}
+	}
+}
+
+func TestScrapeJobSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	cfg := config.ScraperConfig{
+		MaxScrolls:              50,
+		MaxDuration:             time.Hour,
+		InitialDelay:            time.Second,
+		MinDelay:                time.Second,
+		MaxDelay:                3 * time.Second,
+		MaxConsecutiveNoNew:     2,
+		MaxConsecutiveUnchanged: 2,
+		ExtractionInterval:      5,
+		ReplayDir:               t.TempDir(),
+	}
+	ctx := context.Background()
+	collect(t, func(resultsChan chan<- []models.Car) error {
+		source, err := newRecordingSource(ctx, newPagedSource(3, 4), cfg.ReplayDir, cfg)
+		if err != nil {
+			return err
+		}
+		return runScrollLoop(ctx, source, newScrollState(cfg, time.Now), cfg, resultsChan)
+	})
+	collect(t, func(resultsChan chan<- []models.Car) error {
+		return ScrapeCarsWithConfig(ctx, cfg, resultsChan)
+	})
+
+	var job sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "scraper.job" {
+			job = span
+		}
+	}
+	if job == nil {
+		t.Fatal("no scraper.job span")
+	}
+	if !hasAttribute(job, attribute.Bool("scraper.replay", true)) {
+		t.Errorf("job attributes = %v, want scraper.replay=true", job.Attributes())
+	}
+
+	// Each replayed scroll cycle is a child of the job.
+	cycles := 0
+	for _, span := range recorder.Ended() {
+		if span.Name() != "scraper.scroll_cycle" || span.Parent().SpanID() != job.SpanContext().SpanID() {
+			continue
+		}
+		cycles++
+		if !hasAttribute(span, attribute.Int("scraper.cycle", cycles)) {
+			t.Errorf("cycle %d attributes = %v", cycles, span.Attributes())
+		}
+	}
+	if cycles != 4 {
+		t.Errorf("scroll cycle spans under the job = %d, want 4", cycles)
+	}
+}
+
+func hasAttribute(span sdktrace.ReadOnlySpan, want attribute.KeyValue) bool {
+	for _, kv := range span.Attributes() {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/yourusername/car-listing-service/fraud"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// detectFraud runs the fraud rules over every live listing and replaces the
+// stored flags with the ones that fired. It relies on fresh deal scores.
+func (s *carService) detectFraud(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "CarService.detectFraud")
+	defer func() { tracing.End(span, err) }()
+
+	candidates, err := s.repo.FraudCandidates(ctx, time.Now().Add(-fraud.SellerWindow))
+	if err != nil {
+		return err
+	}
+
+	var flags []models.FraudFlag
+	flagged := 0
+	for _, candidate := range candidates {
+		found := fraud.Evaluate(fraud.Rules, candidate)
+		if len(found) > 0 {
+			flagged++
+			flags = append(flags, found...)
+		}
+	}
+	if err := s.repo.ReplaceFraudFlags(ctx, flags); err != nil {
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("fraud.flagged", flagged))
+	slog.InfoContext(ctx, "detected fraud signals", "checked", len(candidates), "flagged", flagged, "flags", len(flags))
+	return nil
+}
+
+// FraudCases lists flagged listings for moderators, highest score first.
+func (s *carService) FraudCases(ctx context.Context, query models.FraudCaseQuery) ([]models.FraudCase, error) {
+	ctx, span := tracer.Start(ctx, "CarService.FraudCases", trace.WithAttributes(attribute.String("fraud.status", string(query.Status))))
+	defer span.End()
+
+	return s.repo.FraudCases(ctx, query)
+}
+
+// ReviewFraud confirms or dismisses a listing as a scam. Confirmed scams
+// drop out of listing queries, statistics and valuations.
+func (s *carService) ReviewFraud(ctx context.Context, id int, status models.FraudReviewStatus, note string) (review *models.FraudReview, err error) {
+	ctx, span := tracer.Start(ctx, "CarService.ReviewFraud", trace.WithAttributes(
+		attribute.Int("car.id", id),
+		attribute.String("fraud.status", string(status)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	review, err = s.repo.ReviewFraud(ctx, id, status, note)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "fraud review recorded", "car_id", id, "status", status)
+	return review, nil
+}
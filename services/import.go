@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/importer"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// importBatchSize is how many valid rows are checked against stored links
+// and inserted at once.
+const importBatchSize = 500
+
+// duplicateInFile marks a row whose link appeared on an earlier line.
+const duplicateInFile = "duplicate_in_file"
+
+// pendingRow is a valid import row waiting for its batch to be stored.
+type pendingRow struct {
+	line int
+	car  models.Car
+}
+
+// ImportListings reads listings from r, normalizes them and stores the ones
+// whose links are new. Invalid rows are rejected and repeated links reported
+// as duplicates; in a dry run nothing is stored and the report shows what
+// would have been. Batches are committed as they fill, so an error part way
+// through leaves the earlier batches stored.
+func (s *carService) ImportListings(ctx context.Context, format importer.Format, r io.Reader, dryRun bool) (report *models.ImportReport, err error) {
+	ctx, span := tracer.Start(ctx, "CarService.ImportListings", trace.WithAttributes(
+		attribute.String("import.format", string(format)),
+		attribute.Bool("import.dry_run", dryRun),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	report = &models.ImportReport{DryRun: dryRun, Rows: []models.ImportRow{}}
+	firstLine := make(map[string]int)
+	var pending []pendingRow
+
+	err = importer.Read(format, r, func(row importer.Row) error {
+		if row.Err != nil {
+			appErr := apperror.From(row.Err)
+			report.Add(models.ImportRow{
+				Line:    row.Line,
+				Status:  models.ImportRejected,
+				Link:    row.Car.Link,
+				Reason:  appErr.Code,
+				Message: appErr.Message,
+				Errors:  appErr.Fields,
+			})
+			return nil
+		}
+
+		car := row.Car.Car()
+		NormalizeCar(&car)
+		if line, ok := firstLine[car.Link]; ok {
+			report.Add(models.ImportRow{
+				Line:    row.Line,
+				Status:  models.ImportDuplicate,
+				Link:    car.Link,
+				Reason:  duplicateInFile,
+				Message: fmt.Sprintf("Same link as line %d", line),
+			})
+			return nil
+		}
+		firstLine[car.Link] = row.Line
+
+		pending = append(pending, pendingRow{line: row.Line, car: car})
+		if len(pending) == importBatchSize {
+			if err := s.importBatch(ctx, report, pending, dryRun); err != nil {
+				return err
+			}
+			pending = pending[:0]
+		}
+		return nil
+	})
+	if err == nil {
+		err = s.importBatch(ctx, report, pending, dryRun)
+	}
+	if !dryRun && report.Accepted > 0 {
+		s.refreshDerived(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(report.Rows, func(i, j int) bool { return report.Rows[i].Line < report.Rows[j].Line })
+	span.SetAttributes(
+		attribute.Int("import.accepted", report.Accepted),
+		attribute.Int("import.duplicates", report.Duplicates),
+		attribute.Int("import.rejected", report.Rejected),
+	)
+	return report, nil
+}
+
+// importBatch stores one batch of valid rows and adds each to the report. A
+// dry run only checks the links against the ones already known.
+func (s *carService) importBatch(ctx context.Context, report *models.ImportReport, rows []pendingRow, dryRun bool) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	statuses := make([]models.ImportRow, len(rows))
+	for i, row := range rows {
+		statuses[i] = models.ImportRow{Line: row.line, Status: models.ImportAccepted, Link: row.car.Link}
+	}
+
+	if dryRun {
+		links := make([]string, len(rows))
+		for i, row := range rows {
+			links[i] = row.car.Link
+		}
+		existingLinks, err := s.repo.FindExistingLinks(ctx, links)
+		if err != nil {
+			return err
+		}
+		for i := range statuses {
+			if existingLinks[statuses[i].Link] {
+				statuses[i].Status = models.ImportDuplicate
+				statuses[i].Reason = models.SkipKnownLink
+			}
+		}
+	} else {
+		cars := make([]models.Car, len(rows))
+		for i, row := range rows {
+			cars[i] = row.car
+		}
+		result, err := s.storeBatch(ctx, cars)
+		if err != nil {
+			return err
+		}
+		for _, skipped := range result.Skipped {
+			statuses[skipped.Index].Status = models.ImportDuplicate
+			statuses[skipped.Index].Reason = skipped.Reason
+			statuses[skipped.Index].Message = skipped.Message
+		}
+		for _, failed := range result.Failed {
+			statuses[failed.Index].Status = models.ImportRejected
+			statuses[failed.Index].Reason = failed.Reason
+			statuses[failed.Index].Message = failed.Message
+		}
+	}
+
+	for _, status := range statuses {
+		report.Add(status)
+	}
+	return nil
+}
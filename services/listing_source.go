@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/chromedp/chromedp"
+)
+
+// ListingSource is the page a scrape session scrolls through. A scroll cycle
+// captures the DOM state, scrolls, captures it again and extracts listings.
+type ListingSource interface {
+	Scroll(ctx context.Context, delay time.Duration) error
+	DOMState(ctx context.Context) (domCount, scrollY int)
+	Listings(ctx context.Context) []models.Car
+}
+
+// sellerSource is implemented by sources that can look up who posted a
+// listing. The feed cards do not show it, so each lookup opens the listing.
+type sellerSource interface {
+	Seller(ctx context.Context, link string) (string, error)
+}
+
+// sellerLookupTimeout bounds one listing page load. A listing without a
+// seller link, such as a removed one, fails the lookup at this point.
+const sellerLookupTimeout = 15 * time.Second
+
+var sellerProfile = regexp.MustCompile(`/marketplace/profile/(\d+)`)
+
+// chromeSource drives a live Marketplace page through chromedp.
+type chromeSource struct{}
+
+func (s *chromeSource) Scroll(ctx context.Context, delay time.Duration) error {
+	return chromedp.Run(ctx,
+		chromedp.Evaluate(`window.scrollBy(0, window.innerHeight)`, nil),
+		chromedp.Sleep(delay),
+	)
+}
+
+func (s *chromeSource) DOMState(ctx context.Context) (domCount, scrollY int) {
+	chromedp.Run(ctx,
+		chromedp.Evaluate(`document.querySelectorAll("a[href*='/marketplace/item/']").length`, &domCount),
+		chromedp.Evaluate(`window.scrollY`, &scrollY),
+	)
+	return
+}
+
+func (s *chromeSource) Listings(ctx context.Context) []models.Car {
+	var listings []models.Car
+	chromedp.Run(ctx, chromedp.Evaluate(`
+		Array.from(document.querySelectorAll("a[href*='/marketplace/item/']")).map(a => {
+			const text = a.innerText.split('\n');
+			let price = "";
+			let title = "";
+			let location = "";
+			let mileage = "";
+
+			text.forEach(line => {
+				if (line.includes("₱") || line.includes("PHP") || line.includes("$")) {
+					price = line;
+				} else if (line.toLowerCase().includes("km")) {
+					mileage = line;
+				} else if (title === "" && line.length > 5) {
+					title = line;
+				} else if (location === "" && title !== "" && line !== price && line !== mileage) {
+					location = line;
+				}
+			});
+
+			const url = new URL(a.href);
+			const cleanLink = url.origin + url.pathname;
+
+			return {
+				title: title,
+				price: price,
+				location: location,
+				mileage: mileage,
+				link: cleanLink
+			};
+		})
+	`, &listings))
+
+	return listings
+}
+
+// Snapshot returns the current document HTML for recordings.
+func (s *chromeSource) Snapshot(ctx context.Context) (string, error) {
+	var html string
+	err := chromedp.Run(ctx, chromedp.OuterHTML("html", &html, chromedp.ByQuery))
+	return html, err
+}
+
+// Seller opens link in a new tab and returns the profile ID of the seller
+// link on the page.
+func (s *chromeSource) Seller(ctx context.Context, link string) (string, error) {
+	tabCtx, cancel := chromedp.NewContext(ctx)
+	defer cancel()
+	tabCtx, cancelTimeout := context.WithTimeout(tabCtx, sellerLookupTimeout)
+	defer cancelTimeout()
+
+	var href string
+	err := chromedp.Run(tabCtx,
+		chromedp.Navigate(link),
+		chromedp.AttributeValue(`a[href*='/marketplace/profile/']`, "href", &href, nil, chromedp.ByQuery),
+	)
+	if err != nil {
+		return "", err
+	}
+	if match := sellerProfile.FindStringSubmatch(href); match != nil {
+		return match[1], nil
+	}
+	return "", nil
+}
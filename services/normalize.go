@@ -0,0 +1,43 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+var titleYear = regexp.MustCompile(`^\s*((?:19|20)\d{2})\b`)
+
+// NormalizeCar trims every field and fills currency and year when they can be
+// derived from the price and title. Scraped listings never carry either.
+func NormalizeCar(car *models.Car) {
+	car.Title = strings.TrimSpace(car.Title)
+	car.Price = strings.TrimSpace(car.Price)
+	car.Currency = strings.ToUpper(strings.TrimSpace(car.Currency))
+	car.Year = strings.TrimSpace(car.Year)
+	car.Mileage = strings.TrimSpace(car.Mileage)
+	car.Location = strings.TrimSpace(car.Location)
+	car.Link = strings.TrimSpace(car.Link)
+	car.Seller = strings.TrimSpace(car.Seller)
+
+	if car.Currency == "" {
+		car.Currency = currencyFromPrice(car.Price)
+	}
+	if car.Year == "" {
+		if match := titleYear.FindStringSubmatch(car.Title); match != nil {
+			car.Year = match[1]
+		}
+	}
+}
+
+func currencyFromPrice(price string) string {
+	switch {
+	case strings.Contains(price, "₱"), strings.Contains(strings.ToUpper(price), "PHP"):
+		return "PHP"
+	case strings.Contains(price, "$"):
+		return "USD"
+	default:
+		return ""
+	}
+}
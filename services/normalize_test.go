@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+func TestNormalizeCar(t *testing.T) {
+	tests := []struct {
+		name string
+		car  models.Car
+		want models.Car
+	}{
+		{
+			name: "scraped",
+			car:  models.Car{Title: " 2016 Honda City 1.5 E ", Price: " ₱390,000 ", Link: "https://example.com/1 "},
+			want: models.Car{Title: "2016 Honda City 1.5 E", Price: "₱390,000", Currency: "PHP", Year: "2016", Link: "https://example.com/1"},
+		},
+		{
+			name: "dollars",
+			car:  models.Car{Title: "Toyota Vios 2018", Price: "$9,000"},
+			want: models.Car{Title: "Toyota Vios 2018", Price: "$9,000", Currency: "USD"},
+		},
+		{
+			name: "given currency and year kept",
+			car:  models.Car{Title: "2016 Honda City", Price: "PHP 390000", Currency: " eur ", Year: "2015"},
+			want: models.Car{Title: "2016 Honda City", Price: "PHP 390000", Currency: "EUR", Year: "2015"},
+		},
+		{
+			name: "nothing to derive",
+			car:  models.Car{Title: "Honda City", Price: "390000"},
+			want: models.Car{Title: "Honda City", Price: "390000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			NormalizeCar(&tt.car)
+			if tt.car != tt.want {
+				t.Errorf("got %+v, want %+v", tt.car, tt.want)
+			}
+		})
+	}
+}
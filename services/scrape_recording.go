@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/models"
+)
+
+const (
+	sessionFile = "session.json"
+	sellersFile = "sellers.json"
+)
+
+var errReplayExhausted = errors.New("replay recordings exhausted")
+
+type recordedSession struct {
+	TargetURL string               `json:"target_url"`
+	StartedAt time.Time            `json:"started_at"`
+	Config    config.ScraperConfig `json:"config"`
+}
+
+type recordedDOMState struct {
+	DOMCount int `json:"dom_count"`
+	ScrollY  int `json:"scroll_y"`
+}
+
+// recordedCycle is everything a scroll cycle observed, in the order
+// performScrollCycle asked for it.
+type recordedCycle struct {
+	Cycle    int              `json:"cycle"`
+	Elapsed  time.Duration    `json:"elapsed"`
+	Delay    time.Duration    `json:"delay"`
+	Before   recordedDOMState `json:"before"`
+	After    recordedDOMState `json:"after"`
+	Listings []models.Car     `json:"listings"`
+	Snapshot string           `json:"snapshot,omitempty"`
+}
+
+type snapshotter interface {
+	Snapshot(ctx context.Context) (string, error)
+}
+
+// recordingSource wraps a live source and writes one recordedCycle per scroll
+// cycle, plus a DOM snapshot when the source supports it.
+type recordingSource struct {
+	source    ListingSource
+	dir       string
+	startTime time.Time
+	current   recordedCycle
+	scrolled  bool
+	sellers   map[string]string
+}
+
+func newRecordingSource(ctx context.Context, source ListingSource, dir string, scraperConfig config.ScraperConfig) (*recordingSource, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	session := recordedSession{
+		TargetURL: scraperConfig.TargetURL,
+		StartedAt: time.Now(),
+		Config:    scraperConfig,
+	}
+	if err := writeJSON(filepath.Join(dir, sessionFile), session); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "recording scrape session", "dir", dir)
+	return &recordingSource{
+		source:    source,
+		dir:       dir,
+		startTime: session.StartedAt,
+		current:   recordedCycle{Cycle: 1},
+		sellers:   make(map[string]string),
+	}, nil
+}
+
+func (s *recordingSource) Scroll(ctx context.Context, delay time.Duration) error {
+	s.current.Delay = delay
+	s.scrolled = true
+	return s.source.Scroll(ctx, delay)
+}
+
+func (s *recordingSource) DOMState(ctx context.Context) (domCount, scrollY int) {
+	domCount, scrollY = s.source.DOMState(ctx)
+	state := recordedDOMState{DOMCount: domCount, ScrollY: scrollY}
+	if s.scrolled {
+		s.current.After = state
+	} else {
+		s.current.Before = state
+	}
+	return domCount, scrollY
+}
+
+func (s *recordingSource) Listings(ctx context.Context) []models.Car {
+	listings := s.source.Listings(ctx)
+
+	cycle := s.current
+	cycle.Listings = listings
+	cycle.Elapsed = time.Since(s.startTime)
+
+	if snap, ok := s.source.(snapshotter); ok {
+		if html, err := snap.Snapshot(ctx); err == nil {
+			cycle.Snapshot = fmt.Sprintf("cycle-%05d.html", cycle.Cycle)
+			if err := os.WriteFile(filepath.Join(s.dir, cycle.Snapshot), []byte(html), 0644); err != nil {
+				slog.WarnContext(ctx, "failed to write DOM snapshot", "cycle", cycle.Cycle, "error", err)
+				cycle.Snapshot = ""
+			}
+		} else {
+			slog.WarnContext(ctx, "failed to capture DOM snapshot", "cycle", cycle.Cycle, "error", err)
+		}
+	}
+
+	if err := writeJSON(filepath.Join(s.dir, fmt.Sprintf("cycle-%05d.json", cycle.Cycle)), cycle); err != nil {
+		slog.WarnContext(ctx, "failed to record scroll cycle", "cycle", cycle.Cycle, "error", err)
+	}
+
+	s.current = recordedCycle{Cycle: cycle.Cycle + 1}
+	s.scrolled = false
+	return listings
+}
+
+// Seller looks up the seller on the live source and records it in
+// sellers.json, rewritten after each lookup so an interrupted session keeps
+// what it found.
+func (s *recordingSource) Seller(ctx context.Context, link string) (string, error) {
+	lookup, ok := s.source.(sellerSource)
+	if !ok {
+		return "", nil
+	}
+	seller, err := lookup.Seller(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	s.sellers[link] = seller
+	if err := writeJSON(filepath.Join(s.dir, sellersFile), s.sellers); err != nil {
+		slog.WarnContext(ctx, "failed to record seller", "link", link, "error", err)
+	}
+	return seller, nil
+}
+
+// replaySource plays back a recorded session without a browser or network.
+// Its clock follows the recorded elapsed times so duration limits trip at the
+// same cycle they did in production.
+type replaySource struct {
+	session  recordedSession
+	cycles   []recordedCycle
+	next     int
+	scrolled bool
+	elapsed  time.Duration
+	sellers  map[string]string
+}
+
+func newReplaySource(ctx context.Context, dir string) (*replaySource, error) {
+	var session recordedSession
+	if err := readJSON(filepath.Join(dir, sessionFile), &session); err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "cycle-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	cycles := make([]recordedCycle, 0, len(files))
+	for _, file := range files {
+		var cycle recordedCycle
+		if err := readJSON(file, &cycle); err != nil {
+			return nil, err
+		}
+		cycles = append(cycles, cycle)
+	}
+
+	// Sessions recorded without seller lookups have no sellers file.
+	sellers := make(map[string]string)
+	if err := readJSON(filepath.Join(dir, sellersFile), &sellers); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "replaying recorded scrape session",
+		"dir", dir, "cycles", len(cycles), "recorded_at", session.StartedAt)
+	return &replaySource{session: session, cycles: cycles, sellers: sellers}, nil
+}
+
+func (s *replaySource) Scroll(ctx context.Context, delay time.Duration) error {
+	if s.next >= len(s.cycles) {
+		return errReplayExhausted
+	}
+	if recorded := s.cycles[s.next].Delay; recorded != delay {
+		slog.WarnContext(ctx, "replay delay differs from recording",
+			"cycle", s.cycles[s.next].Cycle, "delay", delay, "recorded_delay", recorded)
+	}
+	s.scrolled = true
+	return ctx.Err()
+}
+
+func (s *replaySource) DOMState(ctx context.Context) (domCount, scrollY int) {
+	if s.next >= len(s.cycles) {
+		return 0, 0
+	}
+	state := s.cycles[s.next].Before
+	if s.scrolled {
+		state = s.cycles[s.next].After
+	}
+	return state.DOMCount, state.ScrollY
+}
+
+func (s *replaySource) Listings(ctx context.Context) []models.Car {
+	if s.next >= len(s.cycles) {
+		return nil
+	}
+	cycle := s.cycles[s.next]
+	s.next++
+	s.scrolled = false
+	s.elapsed = cycle.Elapsed
+	return cycle.Listings
+}
+
+// Seller returns the recorded seller of link, or "" if it was not looked up.
+func (s *replaySource) Seller(ctx context.Context, link string) (string, error) {
+	return s.sellers[link], ctx.Err()
+}
+
+// Now returns the recorded wall clock at the end of the last replayed cycle.
+func (s *replaySource) Now() time.Time {
+	return s.session.StartedAt.Add(s.elapsed)
+}
+
+func writeJSON(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+func readJSON(filename string, v interface{}) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
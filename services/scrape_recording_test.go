@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/metrics"
+	"github.com/yourusername/car-listing-service/models"
+)
+
+// pagedSource is a feed that loads one more page of listings per scroll
+// until it runs out, after which neither the DOM nor the scroll position
+// change.
+type pagedSource struct {
+	pages  [][]models.Car
+	loaded int
+}
+
+func newPagedSource(pages, pageSize int) *pagedSource {
+	s := &pagedSource{loaded: 1}
+	for page := 0; page < pages; page++ {
+		var cars []models.Car
+		for i := 0; i < pageSize; i++ {
+			id := page*pageSize + i
+			cars = append(cars, models.Car{
+				Title:   fmt.Sprintf("2018 Toyota Vios %d", id),
+				Price:   "₱450,000",
+				Mileage: "60K km",
+				Link:    fmt.Sprintf("https://www.facebook.com/marketplace/item/%d/", id),
+			})
+		}
+		s.pages = append(s.pages, cars)
+	}
+	return s
+}
+
+func (s *pagedSource) Scroll(ctx context.Context, delay time.Duration) error {
+	if s.loaded < len(s.pages) {
+		s.loaded++
+	}
+	return ctx.Err()
+}
+
+func (s *pagedSource) DOMState(ctx context.Context) (domCount, scrollY int) {
+	return len(s.Listings(ctx)), s.loaded * 1000
+}
+
+func (s *pagedSource) Listings(ctx context.Context) []models.Car {
+	var cars []models.Car
+	for _, page := range s.pages[:s.loaded] {
+		cars = append(cars, page...)
+	}
+	return cars
+}
+
+func (s *pagedSource) Seller(ctx context.Context, link string) (string, error) {
+	return "seller of " + link, nil
+}
+
+// collect runs a scrape session and returns every listing it sent.
+func collect(t *testing.T, run func(resultsChan chan<- []models.Car) error) []models.Car {
+	t.Helper()
+	resultsChan := make(chan []models.Car)
+	done := make(chan error, 1)
+	go func() {
+		done <- run(resultsChan)
+		close(resultsChan)
+	}()
+
+	var cars []models.Car
+	for batch := range resultsChan {
+		cars = append(cars, batch...)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	return cars
+}
+
+// stops returns how many sessions have stopped for reason so far.
+func stops(reason string) float64 {
+	return testutil.ToFloat64(metrics.ScraperStops.WithLabelValues(reason))
+}
+
+func checkReplayed(t *testing.T, got, want []models.Car) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("replayed %d listings, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("listing %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRecordAndReplayScrape(t *testing.T) {
+	const pages, pageSize = 3, 4
+	dir := t.TempDir()
+	cfg := config.ScraperConfig{
+		MaxScrolls:              50,
+		MaxDuration:             time.Hour,
+		InitialDelay:            time.Second,
+		MinDelay:                time.Second,
+		MaxDelay:                3 * time.Second,
+		MaxConsecutiveNoNew:     2,
+		MaxConsecutiveUnchanged: 2,
+		ExtractionInterval:      5,
+		FetchSellers:            true,
+	}
+	ctx := context.Background()
+
+	endOfFeed := stops(stopReasonEndOfFeed)
+	recorded := collect(t, func(resultsChan chan<- []models.Car) error {
+		recorder, err := newRecordingSource(ctx, newPagedSource(pages, pageSize), dir, cfg)
+		if err != nil {
+			return err
+		}
+		return runScrollLoop(ctx, recorder, newScrollState(cfg, time.Now), cfg, resultsChan)
+	})
+	if len(recorded) != pages*pageSize {
+		t.Fatalf("recorded session sent %d listings, want %d", len(recorded), pages*pageSize)
+	}
+	for _, car := range recorded {
+		if car.Seller != "seller of "+car.Link {
+			t.Errorf("listing %s has seller %q", car.Link, car.Seller)
+		}
+	}
+	if got := stops(stopReasonEndOfFeed) - endOfFeed; got != 1 {
+		t.Fatalf("recorded session: end_of_feed stops = %v, want 1", got)
+	}
+
+	// The first page is there before the first scroll, so two cycles load the
+	// rest and two more see nothing new and stop the session.
+	cycles, err := filepath.Glob(filepath.Join(dir, "cycle-*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != pages+1 {
+		t.Fatalf("recorded %d cycles, want %d", len(cycles), pages+1)
+	}
+
+	t.Run("full", func(t *testing.T) {
+		endOfFeed := stops(stopReasonEndOfFeed)
+		replayed := collect(t, func(resultsChan chan<- []models.Car) error {
+			return replayScrape(ctx, dir, resultsChan)
+		})
+		checkReplayed(t, replayed, recorded)
+		// The recorded config and DOM states stop the replay where the
+		// session stopped, before it runs out of cycles.
+		if got := stops(stopReasonEndOfFeed) - endOfFeed; got != 1 {
+			t.Errorf("end_of_feed stops = %v, want 1", got)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		if err := os.Remove(cycles[len(cycles)-1]); err != nil {
+			t.Fatal(err)
+		}
+		exhausted := stops(stopReasonReplayExhausted)
+		replayed := collect(t, func(resultsChan chan<- []models.Car) error {
+			return replayScrape(ctx, dir, resultsChan)
+		})
+		checkReplayed(t, replayed, recorded)
+		if got := stops(stopReasonReplayExhausted) - exhausted; got != 1 {
+			t.Errorf("replay_exhausted stops = %v, want 1", got)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/yourusername/car-listing-service/config"
+	"github.com/yourusername/car-listing-service/metrics"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/repository"
+)
+
+// fakeRepository stands in for the database. Methods a test does not expect
+// panic through the nil embedded interface.
+type fakeRepository struct {
+	repository.CarRepository
+	cars   []models.Car
+	known  map[string]bool
+	reject map[string]string
+	// inserted holds the cars of each InsertBatch call.
+	inserted [][]models.Car
+}
+
+// Each visits r.cars in order, ignoring the filter.
+func (r *fakeRepository) Each(ctx context.Context, filter models.CarFilter, fn func(car *models.Car) error) error {
+	for i := range r.cars {
+		car := r.cars[i]
+		if err := fn(&car); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) FindExistingLinks(ctx context.Context, links []string) (map[string]bool, error) {
+	return r.known, nil
+}
+
+// InsertBatch rejects the cars whose links are in r.reject, with the reason
+// given there, and reports the rest as inserted.
+func (r *fakeRepository) InsertBatch(ctx context.Context, cars []models.Car) (models.InsertResult, error) {
+	r.inserted = append(r.inserted, cars)
+	var result models.InsertResult
+	for i, car := range cars {
+		if reason, ok := r.reject[car.Link]; ok {
+			result.Failed = append(result.Failed, models.RowResult{Index: i, Link: car.Link, Reason: reason})
+			continue
+		}
+		result.Inserted++
+	}
+	return result, nil
+}
+
+func TestStoreBatchReportsRows(t *testing.T) {
+	repo := &fakeRepository{
+		known:  map[string]bool{"https://example.com/known": true},
+		reject: map[string]string{"https://example.com/bad": "check_violation"},
+	}
+	s := NewCarService(repo, config.ScraperConfig{}).(*carService)
+	batch := []models.Car{
+		{Title: "2018 Toyota Vios", Link: "https://example.com/known"},
+		{Title: "2016 Honda City", Link: " https://example.com/new "},
+		{Title: "2019 Mitsubishi Mirage", Link: "https://example.com/bad"},
+		{Title: "2017 Suzuki Ertiga", Link: "https://example.com/other"},
+	}
+
+	stored, err := s.storeBatch(context.Background(), batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Known links never reach the insert, and links are normalized first.
+	if len(repo.inserted) != 1 || len(repo.inserted[0]) != 3 || repo.inserted[0][0].Link != "https://example.com/new" {
+		t.Fatalf("inserted %+v, want the three new cars", repo.inserted)
+	}
+	if stored.Inserted != 2 {
+		t.Errorf("inserted = %d, want 2", stored.Inserted)
+	}
+	// Row indexes refer to the batch, not to the cars sent to the insert.
+	if len(stored.Skipped) != 1 || stored.Skipped[0].Index != 0 || stored.Skipped[0].Reason != models.SkipKnownLink {
+		t.Errorf("skipped = %+v, want row 0 as a known link", stored.Skipped)
+	}
+	if len(stored.Failed) != 1 || stored.Failed[0].Index != 2 || stored.Failed[0].Reason != "check_violation" {
+		t.Errorf("failed = %+v, want row 2 as a check violation", stored.Failed)
+	}
+
+	// Job totals number rows across batches.
+	failures := testutil.ToFloat64(metrics.ScraperStoreFailures.WithLabelValues("check_violation"))
+	result := &ScrapeResult{Found: 10}
+	s.recordStored(context.Background(), result, stored)
+	if result.Inserted != 2 || result.Skipped != 1 || result.Failed != 1 {
+		t.Errorf("totals = %+v, want 2 inserted, 1 skipped, 1 failed", result)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Index != 12 || result.Failures[0].Link != "https://example.com/bad" {
+		t.Errorf("failures = %+v, want job row 12", result.Failures)
+	}
+	if got := testutil.ToFloat64(metrics.ScraperStoreFailures.WithLabelValues("check_violation")) - failures; got != 1 {
+		t.Errorf("check_violation store failures grew by %v, want 1", got)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/yourusername/car-listing-service/models"
+	"github.com/yourusername/car-listing-service/tracing"
+	"github.com/yourusername/car-listing-service/valuation"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// valuationCurrency is the currency models are trained and quote in; it is
+// the marketplace's own.
+const valuationCurrency = "PHP"
+
+// Valuate prices a car with the newest trained model.
+func (s *carService) Valuate(ctx context.Context, req models.ValuationRequest) (_ *models.Valuation, err error) {
+	ctx, span := tracer.Start(ctx, "CarService.Valuate")
+	defer func() { tracing.End(span, err) }()
+
+	model, info, err := s.valuationModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	price, low, high, matched := model.Predict(req)
+	span.SetAttributes(attribute.Int("valuation.model_version", info.Version))
+	return &models.Valuation{
+		PredictedPrice: math.Round(price),
+		Low:            math.Round(low),
+		High:           math.Round(high),
+		Confidence:     valuation.Confidence,
+		Currency:       info.Currency,
+		Matched:        matched,
+		ModelVersion:   info.Version,
+		TrainedAt:      info.TrainedAt,
+	}, nil
+}
+
+// valuationModel returns the newest stored model, decoding its parameters
+// only when it is newer than the one already loaded, so models trained by
+// another process are picked up on the next request.
+func (s *carService) valuationModel(ctx context.Context) (*valuation.Model, models.ValuationModelInfo, error) {
+	s.mu.Lock()
+	loaded, info := s.valuation, s.valuationInfo
+	s.mu.Unlock()
+
+	latest, err := s.repo.LatestValuationModel(ctx, valuationCurrency, info.Version)
+	if err != nil {
+		return nil, info, err
+	}
+	if loaded != nil && latest.Version == info.Version {
+		return loaded, info, nil
+	}
+
+	var model valuation.Model
+	if err := json.Unmarshal(latest.Params, &model); err != nil {
+		return nil, info, fmt.Errorf("decode valuation model %d: %w", latest.Version, err)
+	}
+	s.mu.Lock()
+	s.valuation, s.valuationInfo = &model, latest.ValuationModelInfo
+	s.mu.Unlock()
+	return &model, latest.ValuationModelInfo, nil
+}
+
+// TrainValuationModel fits a model to the live listings, stores it as the
+// newest version and starts using it.
+func (s *carService) TrainValuationModel(ctx context.Context) (_ *models.ValuationModelInfo, err error) {
+	ctx, span := tracer.Start(ctx, "CarService.TrainValuationModel")
+	defer func() { tracing.End(span, err) }()
+
+	samples, err := s.repo.ValuationSamples(ctx, valuationCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	model, metrics, err := valuation.Train(samples, started.Year())
+	if errors.Is(err, valuation.ErrInsufficientData) {
+		return nil, apperror.PreconditionFailed("insufficient_training_data", err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	record := &models.ValuationModelRecord{
+		ValuationModelInfo: models.ValuationModelInfo{
+			Currency:     valuationCurrency,
+			TrainingRows: metrics.TrainRows + metrics.TestRows,
+			Metrics:      metrics,
+		},
+		Params: params,
+	}
+	if err := s.repo.SaveValuationModel(ctx, record); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.valuation, s.valuationInfo = model, record.ValuationModelInfo
+	s.mu.Unlock()
+
+	span.SetAttributes(attribute.Int("valuation.model_version", record.Version))
+	slog.InfoContext(ctx, "valuation model trained",
+		"version", record.Version, "rows", record.TrainingRows, "lambda", metrics.Lambda,
+		"mape", metrics.MAPE, "r2", metrics.R2, "duration", time.Since(started))
+	return &record.ValuationModelInfo, nil
+}
+
+// ValuationModels lists the stored model versions with their metrics,
+// newest first.
+func (s *carService) ValuationModels(ctx context.Context) ([]models.ValuationModelInfo, error) {
+	ctx, span := tracer.Start(ctx, "CarService.ValuationModels", trace.WithAttributes(attribute.String("valuation.currency", valuationCurrency)))
+	defer span.End()
+
+	return s.repo.ValuationModels(ctx, valuationCurrency)
+}
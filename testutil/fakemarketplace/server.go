@@ -0,0 +1,345 @@
+// Package fakemarketplace serves a Marketplace-like infinite-scroll car feed
+// and login page so the scraper can be run end-to-end against headless Chrome
+// without touching Facebook.
+package fakemarketplace
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/car-listing-service/config"
+)
+
+const sessionCookie = "c_user"
+
+// EndOfFeed controls what the feed does once every listing has been served.
+type EndOfFeed string
+
+const (
+	// EndStop stops loading anything once the feed is exhausted.
+	EndStop EndOfFeed = "stop"
+	// EndRepeat keeps re-rendering already served listings, growing the DOM
+	// without ever yielding new links.
+	EndRepeat EndOfFeed = "repeat"
+)
+
+type Options struct {
+	Listings     int
+	PageSize     int
+	Duplicates   int
+	LoadDelay    time.Duration
+	EndOfFeed    EndOfFeed
+	RequireLogin bool
+	Email        string
+	Password     string
+	// Sellers is how many sellers the listings are spread over; 0 gives each
+	// listing its own seller.
+	Sellers int
+}
+
+type Listing struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Price    string `json:"price"`
+	Location string `json:"location"`
+	Mileage  string `json:"mileage"`
+}
+
+type Server struct {
+	opts   Options
+	server *httptest.Server
+
+	mu            sync.Mutex
+	pagesServed   int
+	loginAttempts int
+}
+
+var (
+	makes     = []string{"Toyota", "Honda", "Mitsubishi", "Nissan", "Ford", "Hyundai"}
+	models    = []string{"Vios", "City", "Montero", "Navara", "Ranger", "Accent"}
+	locations = []string{"Manila, Philippines", "Quezon City, Philippines", "Makati, Philippines", "Pasig, Philippines"}
+)
+
+func defaultOptions(opts Options) Options {
+	if opts.Listings <= 0 {
+		opts.Listings = 100
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 24
+	}
+	if opts.EndOfFeed == "" {
+		opts.EndOfFeed = EndStop
+	}
+	if opts.Email == "" {
+		opts.Email = "scraper@example.com"
+	}
+	if opts.Password == "" {
+		opts.Password = "password"
+	}
+	return opts
+}
+
+// New starts a server on a random loopback port. Callers must Close it.
+func New(opts Options) *Server {
+	s := NewUnstarted(opts)
+	s.server = httptest.NewServer(s.Handler())
+	return s
+}
+
+// NewUnstarted builds a server whose Handler can be mounted elsewhere.
+func NewUnstarted(opts Options) *Server {
+	return &Server{opts: defaultOptions(opts)}
+}
+
+func (s *Server) Close() {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+func (s *Server) TargetURL() string {
+	return s.URL() + "/marketplace/manila/cars"
+}
+
+func (s *Server) LoginURL() string {
+	return s.URL() + "/login"
+}
+
+// Configure points a scraper config at this server.
+func (s *Server) Configure(cfg *config.ScraperConfig) {
+	cfg.TargetURL = s.TargetURL()
+	cfg.LoginURL = s.LoginURL()
+	cfg.Headless = true
+	cfg.FacebookEmail = s.opts.Email
+	cfg.FacebookPassword = s.opts.Password
+}
+
+// Credentials returns the email and password the fake login page accepts.
+func (s *Server) Credentials() (email, password string) {
+	return s.opts.Email, s.opts.Password
+}
+
+// PagesServed counts feed page requests, including the initial render.
+func (s *Server) PagesServed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pagesServed
+}
+
+func (s *Server) LoginAttempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loginAttempts
+}
+
+// Listing returns the listing the feed renders for id.
+func (s *Server) Listing(id int) Listing {
+	return Listing{
+		ID:       id,
+		Title:    fmt.Sprintf("%d %s %s", 2010+id%14, makes[id%len(makes)], models[id%len(models)]),
+		Price:    "₱" + groupThousands((350+(id*37)%900)*1000),
+		Location: locations[id%len(locations)],
+		Mileage:  fmt.Sprintf("%dK km", 10+(id*13)%150),
+	}
+}
+
+// Seller returns the profile ID linked from the page of listing id.
+func (s *Server) Seller(id int) string {
+	if s.opts.Sellers > 0 {
+		id %= s.opts.Sellers
+	}
+	return strconv.Itoa(100000 + id)
+}
+
+func groupThousands(n int) string {
+	digits := strconv.Itoa(n)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return digits
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", s.handleLogin)
+	mux.HandleFunc("/marketplace/manila/cars", s.requireLogin(s.handleFeedPage))
+	mux.HandleFunc("/marketplace/api/feed", s.requireLogin(s.handleFeedAPI))
+	mux.HandleFunc("/marketplace/item/", s.handleItem)
+	mux.HandleFunc("/", s.handleHome)
+	return mux
+}
+
+func (s *Server) requireLogin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.opts.RequireLogin {
+			if _, err := r.Cookie(sessionCookie); err != nil {
+				http.Redirect(w, r, "/login", http.StatusFound)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		s.mu.Lock()
+		s.loginAttempts++
+		s.mu.Unlock()
+
+		if r.FormValue("email") != s.opts.Email || r.FormValue("pass") != s.opts.Password {
+			w.WriteHeader(http.StatusUnauthorized)
+			loginTemplate.Execute(w, "Wrong credentials")
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "1", Path: "/"})
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+	loginTemplate.Execute(w, "")
+}
+
+func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprint(w, `<!DOCTYPE html><html><body><div role="banner">Marketplace</div></body></html>`)
+}
+
+// handleItem renders a listing page with its seller's profile link.
+func (s *Server) handleItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(r.URL.Path, "/marketplace/item/"), "/"))
+	if err != nil || id < 0 || id >= s.opts.Listings {
+		http.NotFound(w, r)
+		return
+	}
+	listing := s.Listing(id)
+	fmt.Fprintf(w, `<!DOCTYPE html><html><body><h1>%s</h1><a href="/marketplace/profile/%s/">Seller details</a></body></html>`,
+		template.HTMLEscapeString(listing.Title), s.Seller(id))
+}
+
+func (s *Server) handleFeedPage(w http.ResponseWriter, r *http.Request) {
+	feedTemplate.Execute(w, s.page(0))
+}
+
+func (s *Server) handleFeedAPI(w http.ResponseWriter, r *http.Request) {
+	if s.opts.LoadDelay > 0 {
+		time.Sleep(s.opts.LoadDelay)
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.page(page))
+}
+
+type feedPage struct {
+	Page     int       `json:"page"`
+	Listings []Listing `json:"listings"`
+	Done     bool      `json:"done"`
+}
+
+// page builds the listings rendered by the n-th load: the next PageSize
+// unseen listings plus Duplicates re-renders of earlier ones.
+func (s *Server) page(n int) feedPage {
+	s.mu.Lock()
+	s.pagesServed++
+	s.mu.Unlock()
+
+	start := n * s.opts.PageSize
+	end := start + s.opts.PageSize
+	if end > s.opts.Listings {
+		end = s.opts.Listings
+	}
+
+	var listings []Listing
+	for id := start; id < end; id++ {
+		listings = append(listings, s.Listing(id))
+	}
+
+	exhausted := start >= s.opts.Listings
+	if exhausted && s.opts.EndOfFeed == EndStop {
+		return feedPage{Page: n, Done: true}
+	}
+
+	duplicates := s.opts.Duplicates
+	if exhausted {
+		duplicates = s.opts.PageSize
+	}
+	seen := start
+	if seen > s.opts.Listings {
+		seen = s.opts.Listings
+	}
+	for i := 0; i < duplicates && seen > 0; i++ {
+		listings = append(listings, s.Listing((n*7+i)%seen))
+	}
+
+	return feedPage{Page: n, Listings: listings}
+}
+
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html><body>
+{{if .}}<p class="error">{{.}}</p>{{end}}
+<form method="post" action="/login">
+	<input id="email" name="email" type="text">
+	<input id="pass" name="pass" type="password">
+	<button id="loginbutton" type="submit">Log in</button>
+</form>
+</body></html>`))
+
+var feedTemplate = template.Must(template.New("feed").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+	a.listing { display: block; height: 240px; margin: 8px; border: 1px solid #ccc; }
+	a.listing span { display: block; }
+</style>
+</head>
+<body>
+<div role="banner">Marketplace</div>
+<div id="feed">
+{{range .Listings}}<a class="listing" href="/marketplace/item/{{.ID}}/?ref=feed"><span>{{.Price}}</span><span>{{.Title}}</span><span>{{.Location}}</span><span>{{.Mileage}}</span></a>
+{{end}}</div>
+<script>
+	let nextPage = {{.Page}} + 1;
+	let loading = false;
+	let done = {{.Done}};
+
+	function render(listing) {
+		const a = document.createElement("a");
+		a.className = "listing";
+		a.href = "/marketplace/item/" + listing.id + "/?ref=feed";
+		[listing.price, listing.title, listing.location, listing.mileage].forEach(text => {
+			const span = document.createElement("span");
+			span.textContent = text;
+			a.appendChild(span);
+		});
+		document.getElementById("feed").appendChild(a);
+	}
+
+	window.addEventListener("scroll", () => {
+		if (loading || done) return;
+		if (window.innerHeight + window.scrollY < document.body.scrollHeight - 600) return;
+		loading = true;
+		fetch("/marketplace/api/feed?page=" + nextPage)
+			.then(resp => resp.json())
+			.then(page => {
+				(page.listings || []).forEach(render);
+				done = page.done;
+				nextPage++;
+			})
+			.finally(() => { loading = false; });
+	});
+</script>
+</body>
+</html>`))
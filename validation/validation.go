@@ -0,0 +1,184 @@
+// Package validation holds the input rules shared by the HTTP handlers and
+// the importers. Rules are registered on gin's binding validator, so struct
+// tags work the same with c.ShouldBindJSON and with Struct.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/car-listing-service/apperror"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// MinCarYear is the earliest model year accepted, the year the first
+// production automobile was sold.
+const MinCarYear = 1886
+
+// carPrice accepts an optional ₱, PHP or $ prefix followed by an amount,
+// with or without thousands separators, e.g. "₱350,000" or "PHP 1200000.50".
+var carPrice = regexp.MustCompile(`^(?:₱|PHP ?|\$)?(?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d{1,2})?$`)
+
+var marketplaceHosts = map[string]bool{
+	"facebook.com":     true,
+	"www.facebook.com": true,
+	"m.facebook.com":   true,
+	"web.facebook.com": true,
+}
+
+var marketplaceItemPath = regexp.MustCompile(`^/marketplace/item/\d+/?$`)
+
+var (
+	registerOnce sync.Once
+	registerErr  error
+)
+
+// Register adds the car validation tags to gin's validator and reports field
+// errors under their JSON names. It is safe to call more than once; Struct
+// calls it itself, so only callers binding through gin need to.
+func Register() error {
+	registerOnce.Do(func() { registerErr = register() })
+	return registerErr
+}
+
+func register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected binding validator engine")
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	rules := map[string]validator.Func{
+		"car_price":        validateCarPrice,
+		"car_year":         validateCarYear,
+		"marketplace_item": validateMarketplaceItem,
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("register %s validator: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+func validateCarPrice(fl validator.FieldLevel) bool {
+	return carPrice.MatchString(strings.TrimSpace(fl.Field().String()))
+}
+
+// validateCarYear accepts a model year given as a string or an integer.
+func validateCarYear(fl validator.FieldLevel) bool {
+	var year int
+	switch field := fl.Field(); field.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		year = int(field.Int())
+	default:
+		var err error
+		if year, err = strconv.Atoi(strings.TrimSpace(field.String())); err != nil {
+			return false
+		}
+	}
+	return year >= MinCarYear && year <= time.Now().Year()+1
+}
+
+func validateMarketplaceItem(fl validator.FieldLevel) bool {
+	u, err := url.Parse(strings.TrimSpace(fl.Field().String()))
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "https" || u.Scheme == "http") &&
+		marketplaceHosts[strings.ToLower(u.Host)] &&
+		marketplaceItemPath.MatchString(u.Path)
+}
+
+// Struct runs the binding rules on an already decoded value.
+func Struct(obj interface{}) error {
+	if err := Register(); err != nil {
+		return err
+	}
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		return Error(err)
+	}
+	return nil
+}
+
+// Error converts decode and validation failures into a validation error
+// listing each bad field.
+func Error(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]apperror.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, apperror.FieldError{
+				Field:   fieldPath(fe),
+				Code:    fe.Tag(),
+				Message: fieldMessage(fe),
+			})
+		}
+		return apperror.Validation("validation_failed", "Request body failed validation", fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return apperror.Validation("invalid_body", "Request body is not valid JSON for this resource", apperror.FieldError{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be a " + typeErr.Type.String(),
+		})
+	}
+	return apperror.Validation("invalid_body", "Request body is not valid JSON")
+}
+
+// fieldPath is the JSON path of the field within the validated value, such
+// as "car.price", without the Go name of the top-level struct.
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_unless":
+		return "is required for this operation"
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return "must contain at least " + fe.Param() + " items"
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return "must be at most " + fe.Param() + " characters"
+		}
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "car_price":
+		return `must be an amount with an optional ₱, PHP or $ prefix, e.g. "₱350,000"`
+	case "car_year":
+		return fmt.Sprintf("must be a year between %d and %d", MinCarYear, time.Now().Year()+1)
+	case "marketplace_item":
+		return "must be a Facebook Marketplace item URL, e.g. https://www.facebook.com/marketplace/item/123456789/"
+	default:
+		return "is invalid"
+	}
+}
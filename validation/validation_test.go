@@ -0,0 +1,148 @@
+package validation
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-listing-service/apperror"
+)
+
+// failedCodes validates obj and returns the error code of each bad field.
+func failedCodes(t *testing.T, obj interface{}) map[string]string {
+	t.Helper()
+	codes := make(map[string]string)
+	if err := Struct(obj); err != nil {
+		for _, field := range apperror.From(err).Fields {
+			codes[field.Field] = field.Code
+		}
+	}
+	return codes
+}
+
+func TestCarYear(t *testing.T) {
+	next := time.Now().Year() + 1
+	tests := []struct {
+		year string
+		ok   bool
+	}{
+		{"1885", false},
+		{strconv.Itoa(MinCarYear), true},
+		{"2015", true},
+		{" 2015 ", true},
+		{strconv.Itoa(next), true},
+		{strconv.Itoa(next + 1), false},
+		{"2015a", false},
+		{"'15", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.year, func(t *testing.T) {
+			obj := struct {
+				Year string `json:"year" binding:"car_year"`
+			}{tt.year}
+			_, failed := failedCodes(t, &obj)["year"]
+			if failed == tt.ok {
+				t.Errorf("year %q: valid = %v, want %v", tt.year, !failed, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCarYearInt(t *testing.T) {
+	next := time.Now().Year() + 1
+	tests := []struct {
+		year int
+		ok   bool
+	}{
+		{MinCarYear - 1, false},
+		{MinCarYear, true},
+		{next, true},
+		{next + 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.year), func(t *testing.T) {
+			obj := struct {
+				Year int `json:"year" binding:"car_year"`
+			}{tt.year}
+			_, failed := failedCodes(t, &obj)["year"]
+			if failed == tt.ok {
+				t.Errorf("year %d: valid = %v, want %v", tt.year, !failed, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCarPrice(t *testing.T) {
+	tests := []struct {
+		price string
+		ok    bool
+	}{
+		{"350000", true},
+		{"₱350,000", true},
+		{"PHP 1200000.50", true},
+		{"PHP1,200,000", true},
+		{"$5,000.5", true},
+		{" ₱350,000 ", true},
+		{"", false},
+		{"₱", false},
+		{"350,00", false},
+		{"1,2345", false},
+		{"₱ 350,000", false},
+		{"350000.123", false},
+		{"USD 5000", false},
+		{"-5000", false},
+		{"₱350,000 negotiable", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.price, func(t *testing.T) {
+			obj := struct {
+				Price string `json:"price" binding:"car_price"`
+			}{tt.price}
+			_, failed := failedCodes(t, &obj)["price"]
+			if failed == tt.ok {
+				t.Errorf("price %q: valid = %v, want %v", tt.price, !failed, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMarketplaceItem(t *testing.T) {
+	tests := []struct {
+		link string
+		ok   bool
+	}{
+		{"https://www.facebook.com/marketplace/item/123456789/", true},
+		{"https://www.facebook.com/marketplace/item/123456789", true},
+		{"http://m.facebook.com/marketplace/item/1/", true},
+		{"https://web.facebook.com/marketplace/item/1/?ref=search", true},
+		{"https://FACEBOOK.com/marketplace/item/1/", true},
+		{"https://www.facebook.com/marketplace/item/abc/", false},
+		{"https://www.facebook.com/marketplace/item/", false},
+		{"https://www.facebook.com/marketplace/category/cars/", false},
+		{"https://facebook.com.evil.example/marketplace/item/1/", false},
+		{"ftp://www.facebook.com/marketplace/item/1/", false},
+		{"www.facebook.com/marketplace/item/1/", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			obj := struct {
+				Link string `json:"link" binding:"marketplace_item"`
+			}{tt.link}
+			_, failed := failedCodes(t, &obj)["link"]
+			if failed == tt.ok {
+				t.Errorf("link %q: valid = %v, want %v", tt.link, !failed, tt.ok)
+			}
+		})
+	}
+}
+
+func TestErrorUsesJSONNames(t *testing.T) {
+	obj := struct {
+		ModelYear string `json:"model_year" binding:"car_year"`
+	}{"1700"}
+	codes := failedCodes(t, &obj)
+	if codes["model_year"] != "car_year" {
+		t.Errorf("codes = %v, want car_year on model_year", codes)
+	}
+}
@@ -0,0 +1,88 @@
+package valuation
+
+import (
+	"errors"
+	"math"
+)
+
+var errNotPositiveDefinite = errors.New("valuation: normal equations are not positive definite")
+
+// normalEquations accumulates XᵀX and Xᵀy one sparse row at a time.
+type normalEquations struct {
+	n   int
+	xtx []float64 // n×n, row-major
+	xty []float64
+}
+
+func newNormalEquations(n int) *normalEquations {
+	return &normalEquations{n: n, xtx: make([]float64, n*n), xty: make([]float64, n)}
+}
+
+func (e *normalEquations) add(row sparseRow, y float64) {
+	for a, i := range row.index {
+		va := row.value[a]
+		e.xty[i] += va * y
+		for b, j := range row.index {
+			e.xtx[i*e.n+j] += va * row.value[b]
+		}
+	}
+}
+
+// solveRidge returns the coefficients minimising squared error plus lambda
+// times the squared norm of every coefficient but the first, the intercept.
+func (e *normalEquations) solveRidge(lambda float64) ([]float64, error) {
+	a := make([]float64, len(e.xtx))
+	copy(a, e.xtx)
+	for i := 1; i < e.n; i++ {
+		a[i*e.n+i] += lambda
+	}
+	if err := cholesky(a, e.n); err != nil {
+		return nil, err
+	}
+	return choleskySolve(a, e.n, e.xty), nil
+}
+
+// cholesky factors the symmetric positive definite n×n matrix a in place
+// into L with a = L Lᵀ, leaving L in the lower triangle.
+func cholesky(a []float64, n int) error {
+	for j := 0; j < n; j++ {
+		sum := a[j*n+j]
+		for k := 0; k < j; k++ {
+			sum -= a[j*n+k] * a[j*n+k]
+		}
+		if sum <= 0 || math.IsNaN(sum) {
+			return errNotPositiveDefinite
+		}
+		diag := math.Sqrt(sum)
+		a[j*n+j] = diag
+		for i := j + 1; i < n; i++ {
+			sum := a[i*n+j]
+			for k := 0; k < j; k++ {
+				sum -= a[i*n+k] * a[j*n+k]
+			}
+			a[i*n+j] = sum / diag
+		}
+	}
+	return nil
+}
+
+// choleskySolve solves L Lᵀ x = b for the factor left by cholesky.
+func choleskySolve(l []float64, n int, b []float64) []float64 {
+	y := make([]float64, n)
+	for i := 0; i < n; i++ {
+		sum := b[i]
+		for k := 0; k < i; k++ {
+			sum -= l[i*n+k] * y[k]
+		}
+		y[i] = sum / l[i*n+i]
+	}
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := y[i]
+		for k := i + 1; k < n; k++ {
+			sum -= l[k*n+i] * x[k]
+		}
+		x[i] = sum / l[i*n+i]
+	}
+	return x
+}
@@ -0,0 +1,375 @@
+// Package valuation prices cars with a ridge regression of log asking price
+// on age, mileage, make, model and location, trained on stored listings.
+package valuation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+// Confidence is the share of prices the prediction interval is meant to
+// cover.
+const Confidence = 0.8
+
+const (
+	// minSamples is the fewest usable listings a model is trained on.
+	minSamples = 50
+	// minCategoryCount is how often a make, model or location must appear to
+	// get its own coefficient; rarer ones share the intercept.
+	minCategoryCount = 5
+	// maxCategories caps the coefficients per kind of category, keeping the
+	// most frequent.
+	maxCategories = 500
+	// holdoutFraction of the listings is held out to choose lambda and to
+	// evaluate the model.
+	holdoutFraction = 0.2
+)
+
+// lambdas are the ridge penalties tried, the one with the lowest held-out
+// error winning.
+var lambdas = []float64{0.1, 0.3, 1, 3, 10, 30}
+
+var ErrInsufficientData = errors.New("not enough listings to train a valuation model")
+
+// scaler standardizes a numeric feature.
+type scaler struct {
+	Mean float64 `json:"mean"`
+	Std  float64 `json:"std"`
+}
+
+func newScaler(values []float64) scaler {
+	if len(values) == 0 {
+		return scaler{Std: 1}
+	}
+	var sum, sumSquares float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	for _, v := range values {
+		sumSquares += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(sumSquares / float64(len(values)))
+	if std == 0 {
+		std = 1
+	}
+	return scaler{Mean: mean, Std: std}
+}
+
+func (s scaler) apply(v float64) float64 {
+	return (v - s.Mean) / s.Std
+}
+
+// Model is a trained valuation model. It is stored as JSON, so its fields
+// are the model's serialized form.
+type Model struct {
+	ReferenceYear int     `json:"reference_year"`
+	Lambda        float64 `json:"lambda"`
+	Intercept     float64 `json:"intercept"`
+	Age           scaler  `json:"age"`
+	AgeSquared    scaler  `json:"age_squared"`
+	LogMileage    scaler  `json:"log_mileage"`
+	// Weights holds the coefficient of every feature but the intercept:
+	// the numeric ones and "make:", "model:" and "location:" categories.
+	Weights map[string]float64 `json:"weights"`
+	// ResidualLow and ResidualHigh bound the held-out log price residuals
+	// of the central Confidence share.
+	ResidualLow  float64 `json:"residual_low"`
+	ResidualHigh float64 `json:"residual_high"`
+}
+
+type feature struct {
+	name  string
+	value float64
+}
+
+type sparseRow struct {
+	index []int
+	value []float64
+}
+
+// categoryKey lowercases s and collapses its whitespace, matching how make
+// and model are parsed from titles.
+func categoryKey(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// features encodes a car. Categories are only included when known reports
+// them, and matched says which were.
+func (m *Model) features(carMake, model string, year int, mileageKm *float64, location string, known func(string) bool) ([]feature, models.ValuationMatch) {
+	age := float64(m.ReferenceYear - year)
+	feats := []feature{
+		{"age", m.Age.apply(age)},
+		{"age_squared", m.AgeSquared.apply(age * age)},
+	}
+	if mileageKm != nil && *mileageKm >= 0 {
+		feats = append(feats, feature{"log_mileage", m.LogMileage.apply(math.Log1p(*mileageKm))})
+	} else {
+		feats = append(feats, feature{"mileage_missing", 1})
+	}
+
+	var matched models.ValuationMatch
+	makeKey, modelKey, locationKey := categoryKey(carMake), categoryKey(model), categoryKey(location)
+	if name := "make:" + makeKey; makeKey != "" && known(name) {
+		feats = append(feats, feature{name, 1})
+		matched.Make = true
+	}
+	if name := "model:" + makeKey + " " + modelKey; modelKey != "" && known(name) {
+		feats = append(feats, feature{name, 1})
+		matched.Model = true
+	}
+	if name := "location:" + locationKey; locationKey != "" && known(name) {
+		feats = append(feats, feature{name, 1})
+		matched.Location = true
+	}
+	return feats, matched
+}
+
+// Predict returns the predicted price and its interval for req.
+func (m *Model) Predict(req models.ValuationRequest) (price, low, high float64, matched models.ValuationMatch) {
+	known := func(name string) bool {
+		_, ok := m.Weights[name]
+		return ok
+	}
+	feats, matched := m.features(req.Make, req.Model, req.Year, req.MileageKm, req.Location, known)
+	logPrice := m.logPrice(feats)
+	return math.Exp(logPrice), math.Exp(logPrice + m.ResidualLow), math.Exp(logPrice + m.ResidualHigh), matched
+}
+
+func (m *Model) logPrice(feats []feature) float64 {
+	sum := m.Intercept
+	for _, f := range feats {
+		sum += m.Weights[f.name] * f.value
+	}
+	return sum
+}
+
+func (m *Model) predictSample(s models.ValuationSample) float64 {
+	known := func(name string) bool {
+		_, ok := m.Weights[name]
+		return ok
+	}
+	feats, _ := m.features(s.Make, s.Model, s.Year, s.MileageKm, s.Location, known)
+	return m.logPrice(feats)
+}
+
+// Train fits a model to samples, ages counted from referenceYear. A share of
+// the samples is held out to pick the ridge penalty, evaluate the model and
+// size its prediction interval; the returned model is then refitted on all
+// of them.
+func Train(samples []models.ValuationSample, referenceYear int) (*Model, models.ValuationMetrics, error) {
+	var metrics models.ValuationMetrics
+	samples = usableSamples(samples)
+	if len(samples) < minSamples {
+		return nil, metrics, fmt.Errorf("%w: have %d, need %d", ErrInsufficientData, len(samples), minSamples)
+	}
+
+	// A fixed seed keeps retraining on the same listings reproducible.
+	rand.New(rand.NewSource(1)).Shuffle(len(samples), func(i, j int) {
+		samples[i], samples[j] = samples[j], samples[i]
+	})
+	nTest := int(float64(len(samples)) * holdoutFraction)
+	test, train := samples[:nTest], samples[nTest:]
+
+	var best *Model
+	bestRMSE := math.Inf(1)
+	for _, lambda := range lambdas {
+		m, err := fit(train, lambda, referenceYear)
+		if err != nil {
+			continue
+		}
+		var sumSquares float64
+		for _, s := range test {
+			r := math.Log(s.Price) - m.predictSample(s)
+			sumSquares += r * r
+		}
+		if rmse := math.Sqrt(sumSquares / float64(len(test))); rmse < bestRMSE {
+			best, bestRMSE = m, rmse
+		}
+	}
+	if best == nil {
+		return nil, metrics, errNotPositiveDefinite
+	}
+
+	metrics = evaluate(best, test)
+	metrics.TrainRows = len(train)
+	metrics.TestRows = len(test)
+	residuals := make([]float64, len(test))
+	for i, s := range test {
+		residuals[i] = math.Log(s.Price) - best.predictSample(s)
+	}
+
+	final, err := fit(samples, best.Lambda, referenceYear)
+	if err != nil {
+		return nil, metrics, err
+	}
+	final.ResidualLow = quantile(residuals, (1-Confidence)/2)
+	final.ResidualHigh = quantile(residuals, (1+Confidence)/2)
+	return final, metrics, nil
+}
+
+// usableSamples drops listings without a price, make or year and trims the
+// cheapest and dearest percent, which are mostly placeholder prices.
+func usableSamples(samples []models.ValuationSample) []models.ValuationSample {
+	var usable []models.ValuationSample
+	for _, s := range samples {
+		if s.Price > 0 && categoryKey(s.Make) != "" && s.Year > 0 {
+			usable = append(usable, s)
+		}
+	}
+	if len(usable) < 100 {
+		return usable
+	}
+
+	logPrices := make([]float64, len(usable))
+	for i, s := range usable {
+		logPrices[i] = math.Log(s.Price)
+	}
+	low, high := quantile(logPrices, 0.01), quantile(logPrices, 0.99)
+	trimmed := usable[:0]
+	for i, s := range usable {
+		if logPrices[i] >= low && logPrices[i] <= high {
+			trimmed = append(trimmed, s)
+		}
+	}
+	return trimmed
+}
+
+func fit(samples []models.ValuationSample, lambda float64, referenceYear int) (*Model, error) {
+	m := &Model{ReferenceYear: referenceYear, Lambda: lambda, Weights: make(map[string]float64)}
+
+	var ages, agesSquared, logMileages []float64
+	for _, s := range samples {
+		age := float64(referenceYear - s.Year)
+		ages = append(ages, age)
+		agesSquared = append(agesSquared, age*age)
+		if s.MileageKm != nil && *s.MileageKm >= 0 {
+			logMileages = append(logMileages, math.Log1p(*s.MileageKm))
+		}
+	}
+	m.Age, m.AgeSquared, m.LogMileage = newScaler(ages), newScaler(agesSquared), newScaler(logMileages)
+
+	names := []string{"intercept", "age", "age_squared", "log_mileage", "mileage_missing"}
+	names = append(names, vocabulary(samples)...)
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	known := func(name string) bool {
+		_, ok := index[name]
+		return ok
+	}
+
+	eq := newNormalEquations(len(names))
+	for _, s := range samples {
+		feats, _ := m.features(s.Make, s.Model, s.Year, s.MileageKm, s.Location, known)
+		row := sparseRow{index: []int{0}, value: []float64{1}}
+		for _, f := range feats {
+			row.index = append(row.index, index[f.name])
+			row.value = append(row.value, f.value)
+		}
+		eq.add(row, math.Log(s.Price))
+	}
+
+	coefficients, err := eq.solveRidge(lambda)
+	if err != nil {
+		return nil, err
+	}
+	m.Intercept = coefficients[0]
+	for i, name := range names[1:] {
+		m.Weights[name] = coefficients[i+1]
+	}
+	return m, nil
+}
+
+// vocabulary lists the make, model and location features common enough in
+// samples to get a coefficient.
+func vocabulary(samples []models.ValuationSample) []string {
+	counts := map[string]map[string]int{"make": {}, "model": {}, "location": {}}
+	for _, s := range samples {
+		makeKey, modelKey, locationKey := categoryKey(s.Make), categoryKey(s.Model), categoryKey(s.Location)
+		counts["make"]["make:"+makeKey]++
+		if modelKey != "" {
+			counts["model"]["model:"+makeKey+" "+modelKey]++
+		}
+		if locationKey != "" {
+			counts["location"]["location:"+locationKey]++
+		}
+	}
+
+	var names []string
+	for _, kind := range []string{"make", "model", "location"} {
+		var frequent []string
+		for name, n := range counts[kind] {
+			if n >= minCategoryCount {
+				frequent = append(frequent, name)
+			}
+		}
+		sort.Slice(frequent, func(i, j int) bool {
+			ni, nj := counts[kind][frequent[i]], counts[kind][frequent[j]]
+			if ni != nj {
+				return ni > nj
+			}
+			return frequent[i] < frequent[j]
+		})
+		if len(frequent) > maxCategories {
+			frequent = frequent[:maxCategories]
+		}
+		names = append(names, frequent...)
+	}
+	return names
+}
+
+// evaluate scores m's predicted prices against the actual ones.
+func evaluate(m *Model, test []models.ValuationSample) models.ValuationMetrics {
+	metrics := models.ValuationMetrics{Lambda: m.Lambda}
+	if len(test) == 0 {
+		return metrics
+	}
+
+	var absSum, squareSum, pctSum, mean float64
+	for _, s := range test {
+		mean += s.Price
+	}
+	mean /= float64(len(test))
+
+	var totalSquares float64
+	for _, s := range test {
+		diff := math.Exp(m.predictSample(s)) - s.Price
+		absSum += math.Abs(diff)
+		squareSum += diff * diff
+		pctSum += math.Abs(diff) / s.Price
+		totalSquares += (s.Price - mean) * (s.Price - mean)
+	}
+	n := float64(len(test))
+	metrics.MAE = absSum / n
+	metrics.RMSE = math.Sqrt(squareSum / n)
+	metrics.MAPE = pctSum / n
+	if totalSquares > 0 {
+		metrics.R2 = 1 - squareSum/totalSquares
+	}
+	return metrics
+}
+
+// quantile interpolates the q-th quantile of values, which it does not
+// modify.
+func quantile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower]*(1-frac) + sorted[lower+1]*frac
+}
@@ -0,0 +1,234 @@
+package valuation
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/yourusername/car-listing-service/models"
+)
+
+const testReferenceYear = 2026
+
+// Effects on log price used to generate synthetic listings.
+const (
+	baseLogPrice    = 13.5
+	agePerYear      = -0.08
+	logMileageSlope = -0.15
+	noiseStd        = 0.1
+)
+
+var (
+	makeEffects     = map[string]float64{"toyota": 0.25, "honda": 0.1, "kia": -0.2}
+	locationEffects = map[string]float64{"makati": 0.1, "cebu": -0.05}
+)
+
+// syntheticSamples draws n listings whose log price is a known linear
+// function of the features plus Gaussian noise.
+func syntheticSamples(rng *rand.Rand, n int) []models.ValuationSample {
+	makes := []string{"toyota", "honda", "kia"}
+	locations := []string{"makati", "cebu"}
+	samples := make([]models.ValuationSample, n)
+	for i := range samples {
+		carMake, location := makes[rng.Intn(len(makes))], locations[rng.Intn(len(locations))]
+		year := testReferenceYear - 1 - rng.Intn(12)
+		mileage := 5000 + rng.Float64()*150000
+		logPrice := baseLogPrice +
+			agePerYear*float64(testReferenceYear-year) +
+			logMileageSlope*math.Log1p(mileage) +
+			makeEffects[carMake] + locationEffects[location] +
+			rng.NormFloat64()*noiseStd
+		samples[i] = models.ValuationSample{
+			Make:      carMake,
+			Year:      year,
+			MileageKm: &mileage,
+			Location:  location,
+			Price:     math.Exp(logPrice),
+		}
+	}
+	return samples
+}
+
+func TestCholeskySolve(t *testing.T) {
+	// a = L Lᵀ with L = [[2 0 0] [1 3 0] [0.5 1 1]].
+	a := []float64{
+		4, 2, 1,
+		2, 10, 3.5,
+		1, 3.5, 2.25,
+	}
+	want := []float64{1, -2, 3}
+	b := make([]float64, 3)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			b[i] += a[i*3+j] * want[j]
+		}
+	}
+
+	if err := cholesky(a, 3); err != nil {
+		t.Fatal(err)
+	}
+	got := choleskySolve(a, 3, b)
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("x = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestCholeskyRejectsIndefinite(t *testing.T) {
+	a := []float64{1, 2, 2, 1}
+	if err := cholesky(a, 2); !errors.Is(err, errNotPositiveDefinite) {
+		t.Errorf("err = %v, want errNotPositiveDefinite", err)
+	}
+}
+
+func TestSolveRidgeRecoversCoefficients(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	want := []float64{2, -1.5, 0.75, 3}
+	eq := newNormalEquations(len(want))
+	for i := 0; i < 500; i++ {
+		row := sparseRow{index: []int{0, 1, 2, 3}, value: []float64{1, rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()}}
+		var y float64
+		for k, v := range row.value {
+			y += want[k] * v
+		}
+		eq.add(row, y)
+	}
+
+	got, err := eq.solveRidge(1e-9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-6 {
+			t.Fatalf("coefficients = %v, want %v", got, want)
+		}
+	}
+
+	// A large penalty shrinks every coefficient but the intercept.
+	shrunk, err := eq.solveRidge(1e6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(want); i++ {
+		if math.Abs(shrunk[i]) >= math.Abs(got[i])/10 {
+			t.Errorf("coefficient %d = %v with a large penalty, want it shrunk from %v", i, shrunk[i], got[i])
+		}
+	}
+}
+
+func TestTrainRecoversEffects(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	model, metrics, err := Train(syntheticSamples(rng, 3000), testReferenceYear)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metrics.TrainRows == 0 || metrics.TestRows == 0 {
+		t.Errorf("metrics rows = %d train, %d test", metrics.TrainRows, metrics.TestRows)
+	}
+	if !containsFloat(lambdas, model.Lambda) || metrics.Lambda != model.Lambda {
+		t.Errorf("lambda = %v (metrics %v), want one of %v", model.Lambda, metrics.Lambda, lambdas)
+	}
+	if metrics.R2 < 0.8 {
+		t.Errorf("held-out R² = %.3f, want at least 0.8", metrics.R2)
+	}
+
+	// Category effects are relative to each other: the intercept absorbs
+	// their common level.
+	const tolerance = 0.03
+	checkDiff := func(name string, got, want float64) {
+		t.Helper()
+		if math.Abs(got-want) > tolerance {
+			t.Errorf("%s = %.3f, want %.3f ± %.2f", name, got, want, tolerance)
+		}
+	}
+	checkDiff("toyota - kia", model.Weights["make:toyota"]-model.Weights["make:kia"], makeEffects["toyota"]-makeEffects["kia"])
+	checkDiff("honda - kia", model.Weights["make:honda"]-model.Weights["make:kia"], makeEffects["honda"]-makeEffects["kia"])
+	checkDiff("makati - cebu", model.Weights["location:makati"]-model.Weights["location:cebu"], locationEffects["makati"]-locationEffects["cebu"])
+
+	// Age and mileage are standardized, so compare predictions instead.
+	mileage := 60000.0
+	predict := func(year int, mileageKm float64) float64 {
+		price, _, _, _ := model.Predict(models.ValuationRequest{Make: "Toyota", Year: year, MileageKm: &mileageKm, Location: "Makati"})
+		return math.Log(price)
+	}
+	checkDiff("log price per 4 years of age", predict(2020, mileage)-predict(2024, mileage), 4*agePerYear)
+	checkDiff("log price per doubled mileage", predict(2020, 2*mileage)-predict(2020, mileage),
+		logMileageSlope*(math.Log1p(2*mileage)-math.Log1p(mileage)))
+}
+
+func TestTrainIntervalCoverage(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	model, _, err := Train(syntheticSamples(rng, 3000), testReferenceYear)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unseen := syntheticSamples(rng, 2000)
+	covered := 0
+	for _, s := range unseen {
+		_, low, high, matched := model.Predict(models.ValuationRequest{
+			Make: s.Make, Year: s.Year, MileageKm: s.MileageKm, Location: s.Location,
+		})
+		if !matched.Make || !matched.Location {
+			t.Fatalf("categories of %+v not matched", s)
+		}
+		if low >= high {
+			t.Fatalf("interval [%v, %v] is empty", low, high)
+		}
+		if s.Price >= low && s.Price <= high {
+			covered++
+		}
+	}
+
+	share := float64(covered) / float64(len(unseen))
+	if math.Abs(share-Confidence) > 0.05 {
+		t.Errorf("interval covers %.3f of unseen prices, want %.2f ± 0.05", share, Confidence)
+	}
+}
+
+func TestTrainInsufficientData(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+
+	if _, _, err := Train(syntheticSamples(rng, minSamples-1), testReferenceYear); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("%d samples: err = %v, want ErrInsufficientData", minSamples-1, err)
+	}
+
+	// Listings without a make, year or price are not usable.
+	samples := syntheticSamples(rng, minSamples+10)
+	for i := 0; i < 11; i++ {
+		samples[i].Make = " "
+	}
+	if _, _, err := Train(samples, testReferenceYear); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("%d usable samples: err = %v, want ErrInsufficientData", minSamples-1, err)
+	}
+
+	if _, _, err := Train(syntheticSamples(rng, minSamples), testReferenceYear); err != nil {
+		t.Errorf("%d samples: err = %v, want a model", minSamples, err)
+	}
+}
+
+func TestPredictUnknownCategories(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	model, _, err := Train(syntheticSamples(rng, 500), testReferenceYear)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, matched := model.Predict(models.ValuationRequest{Make: "Lada", Model: "Niva", Year: 2015, Location: "Davao"})
+	if matched.Make || matched.Model || matched.Location {
+		t.Errorf("matched = %+v, want nothing matched", matched)
+	}
+}
+
+func containsFloat(values []float64, v float64) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}